@@ -39,15 +39,20 @@ func parseAllOf(node schemaNode, metadata *parserMetadata) (Generator, error) {
 	node.AllOf = nil
 	nodesToCombine = append(nodesToCombine, node)
 
+	// The merge lets the last type win so a generator can still be built. Report the conflict regardless
+	if err := checkAllOfTypes(nodesToCombine); err != nil {
+		metadata.Errors.AddErrorWithSubpath("/type", newContradictoryAllOfError(err))
+	}
+
 	mergedNode, err := mergeSchemaNodes(metadata, nodesToCombine...)
 	if err != nil {
-		return &nullGenerator{}, err
+		return &nullGenerator{}, newContradictoryAllOfError(err)
 	}
 
 	generator, err := metadata.ReferenceHandler.ParseNodeInScope("/allOf", mergedNode, metadata, nodesToCombine...)
 
 	if err != nil {
-		return &nullGenerator{}, err
+		return &nullGenerator{}, newContradictoryAllOfError(err)
 	}
 
 	return &allOfGenerator{
@@ -55,6 +60,50 @@ func parseAllOf(node schemaNode, metadata *parserMetadata) (Generator, error) {
 	}, nil
 }
 
+// Returns an error if the types of the given nodes have nothing in common
+func checkAllOfTypes(nodes []schemaNode) error {
+	var allowed []string
+	for _, node := range nodes {
+		if node.Type == nil {
+			continue
+		}
+
+		types := node.Type.MultipleTypes
+		if node.Type.SingleType != "" {
+			types = []string{node.Type.SingleType}
+		}
+
+		if allowed == nil {
+			allowed = types
+			continue
+		}
+
+		intersection := []string{}
+		for _, left := range allowed {
+			for _, right := range types {
+				switch {
+				case left == right:
+					intersection = append(intersection, left)
+				case left == typeInteger && right == typeNumber, left == typeNumber && right == typeInteger:
+					intersection = append(intersection, typeInteger)
+				}
+			}
+		}
+
+		if len(intersection) == 0 {
+			return fmt.Errorf("type intersection is empty left: %v, right: %v", allowed, types)
+		}
+
+		allowed = intersection
+	}
+
+	return nil
+}
+
+func newContradictoryAllOfError(err error) error {
+	return newDiagnosticError(DiagnosticSeverityError, DiagnosticCodeContradictoryAllOf, fmt.Errorf("allOf sub-schemas contradict each other: %w", err))
+}
+
 func (g *allOfGenerator) Generate(opts *GeneratorOptions) interface{} {
 	return g.Generator.Generate(opts)
 }
@@ -62,3 +111,8 @@ func (g *allOfGenerator) Generate(opts *GeneratorOptions) interface{} {
 func (g *allOfGenerator) String() string {
 	return fmt.Sprintf("AllOfGenerator[%s]", g.Generator)
 }
+
+func (g *allOfGenerator) describe(node *GeneratorNode) {
+	node.Kind = GeneratorKindAllOf
+	node.addChild("allOf", "", g.Generator)
+}
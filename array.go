@@ -2,6 +2,7 @@ package chaff
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/ryanolee/go-chaff/internal/util"
 	"github.com/thoas/go-funk"
@@ -74,7 +75,9 @@ func parseArray(node schemaNode, metadata *parserMetadata) (Generator, error) {
 	}
 
 	min := util.GetInt(minItems, minContains)
-	max := util.GetInt(maxItems, min+defaultOffset)
+
+	// Arrays without "maxItems" are given a default maximum when generated (See GeneratorOptions.DefaultArrayMaxItems)
+	max := maxItems
 
 	disallowedAdditionalItems := (node.Items != nil && node.Items.DisallowAdditionalItems) || (node.AdditionalItems != nil && node.AdditionalItems.IsFalse)
 
@@ -218,8 +221,8 @@ func (g arrayGenerator) Generate(opts *GeneratorOptions) interface{} {
 	arrayData := make([]interface{}, 0)
 
 	if tupleLength != 0 {
-		for _, generator := range g.TupleGenerators {
-			arrayData = append(arrayData, generator.Generate(opts))
+		for i, generator := range g.TupleGenerators {
+			arrayData = append(arrayData, generateAtPath(opts, strconv.Itoa(i), generator))
 		}
 	}
 
@@ -242,7 +245,7 @@ func (g arrayGenerator) Generate(opts *GeneratorOptions) interface{} {
 	}
 
 	minItems := util.GetInt(g.MinItems, opts.DefaultArrayMinItems)
-	maxItems := util.GetInt(g.MaxItems, opts.DefaultArrayMaxItems)
+	maxItems := util.GetInt(g.MaxItems, util.GetInt(opts.DefaultArrayMaxItems, minItems+defaultOffset))
 
 	// Handle cases where no min items are handled
 	minContains := 0
@@ -263,7 +266,7 @@ func (g arrayGenerator) Generate(opts *GeneratorOptions) interface{} {
 	}
 
 	if maxItems < minItems {
-		maxItems = minItems + opts.DefaultArrayMaxItems
+		maxItems = minItems + util.GetInt(opts.DefaultArrayMaxItems, defaultOffset)
 	}
 
 	// Compute how many items we can generate over the minimum satisfiable set of data)
@@ -304,19 +307,39 @@ func (g arrayGenerator) String() string {
 
 // Will attempt to generate a unique item if the uniqueItems flag is set
 func (g arrayGenerator) generateConsideringUnique(opts *GeneratorOptions, itemGenerator Generator, arrayData []interface{}) (interface{}, bool) {
+	index := strconv.Itoa(len(arrayData))
 	if !g.UniqueItems {
-		return itemGenerator.Generate(opts), true
+		return generateAtPath(opts, index, itemGenerator), true
 	}
 
-	currentItems := funk.Map(arrayData, util.MarshalJsonToString).([]string)
+	// Items are compared without their property order (See GeneratorOptions.PreservePropertyOrder)
+	currentItems := funk.Map(arrayData, func(item interface{}) string {
+		return util.MarshalJsonToString(unorderedValue(item))
+	}).([]string)
 
 	// Generate until we have a unique item
 	for i := 0; i < opts.MaximumUniqueGeneratorAttempts; i++ {
-		item := itemGenerator.Generate(opts)
-		if !funk.Contains(currentItems, util.MarshalJsonToString(item)) {
+		item := generateAtPath(opts, index, itemGenerator)
+		if !funk.Contains(currentItems, util.MarshalJsonToString(unorderedValue(item))) {
 			return item, true
 		}
 	}
 
 	return fmt.Sprintf("Warning: Unable to generate unique item after %d attempts. Recheck passed schema.", opts.MaximumUniqueGeneratorAttempts), false
 }
+
+func (g arrayGenerator) describe(node *GeneratorNode) {
+	node.Kind = GeneratorKindArray
+	node.Bounds.MinItems = intBound(g.MinItems)
+	node.Bounds.MaxItems = intBound(g.MaxItems)
+	node.Bounds.MinContains = intBound(g.MinContains)
+	node.Bounds.UniqueItems = g.UniqueItems
+	for i, generator := range g.TupleGenerators {
+		node.addChild("prefixItems", strconv.Itoa(i), generator)
+	}
+
+	node.addChild("items", "", g.ItemGenerator)
+	node.addChild("additionalItems", "", g.AdditionalItemsGenerator)
+	node.addChild("unevaluatedItems", "", g.UnevaluatedItemsGenerator)
+	node.addChild("contains", "", g.ContainsGenerator)
+}
@@ -3,6 +3,7 @@ package chaff_test
 import (
 	"testing"
 
+	"github.com/ryanolee/go-chaff"
 	test "github.com/ryanolee/go-chaff/internal/test_utils"
 )
 
@@ -10,3 +11,27 @@ func TestArray(t *testing.T) {
 	t.Parallel()
 	test.TestJsonSchemaDir(t, "test_data/array", 100)
 }
+
+func TestArrayDefaultMaxItems(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{"type": "array", "items": {"const": 1}, "minItems": 15}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	for _, testCase := range []struct {
+		opts     chaff.GeneratorOptions
+		min, max int
+	}{
+		{opts: chaff.GeneratorOptions{}, min: 15, max: 25},
+		{opts: chaff.GeneratorOptions{DefaultArrayMaxItems: 17}, min: 15, max: 17},
+		{opts: chaff.GeneratorOptions{DefaultArrayMaxItems: 3}, min: 15, max: 18},
+	} {
+		for i := 0; i < 50; i++ {
+			result := generator.Generate(&testCase.opts).([]interface{})
+			if len(result) < testCase.min || len(result) > testCase.max {
+				t.Fatalf("Expected between %d and %d items with %+v, got %d", testCase.min, testCase.max, testCase.opts, len(result))
+			}
+		}
+	}
+}
@@ -19,7 +19,7 @@ func assertLowerUpperBound(lower int, upper int, lowerName string, upperName str
 	}
 
 	if lower > upper {
-		return fmt.Errorf("%s must be less than or equal to %s (%s: %d, %s: %d)", lowerName, upperName, lowerName, lower, upperName, upper)
+		return newDiagnosticError(DiagnosticSeverityError, DiagnosticCodeUnsatisfiable, fmt.Errorf("%s must be less than or equal to %s (%s: %d, %s: %d)", lowerName, upperName, lowerName, lower, upperName, upper))
 	}
 
 	return nil
@@ -27,10 +27,10 @@ func assertLowerUpperBound(lower int, upper int, lowerName string, upperName str
 
 func assertNoUnsupported(node schemaNode) error {
 	if len(node.DependentRequired) > 0 {
-		return fmt.Errorf("'dependentRequired' is not supported")
+		return newDiagnosticError(DiagnosticSeverityUnsupported, DiagnosticCodeUnsupportedKeyword, fmt.Errorf("'dependentRequired' is not supported"))
 	}
 	if len(node.DependentSchemas) > 0 {
-		return fmt.Errorf("'dependentSchemas' is not supported")
+		return newDiagnosticError(DiagnosticSeverityUnsupported, DiagnosticCodeUnsupportedKeyword, fmt.Errorf("'dependentSchemas' is not supported"))
 	}
 	return nil
 }
@@ -20,3 +20,7 @@ func (g booleanGenerator) Generate(opts *GeneratorOptions) interface{} {
 func (g booleanGenerator) String() string {
 	return "BooleanGenerator"
 }
+
+func (g booleanGenerator) describe(node *GeneratorNode) {
+	node.Kind = GeneratorKindBoolean
+}
@@ -0,0 +1,277 @@
+package chaff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/ryanolee/go-chaff/internal/util"
+)
+
+type (
+	// Embeds the external documents referenced by a schema into it
+	schemaBundler struct {
+		resolver *documentResolver
+
+		// IDs the root document can be referred to by
+		rootDocumentIds []string
+
+		// Keys in the root "$defs" of each embedded document
+		keys map[string]string
+
+		// Embedded documents by their key in the root "$defs"
+		definitions map[string]interface{}
+
+		// Embedded documents that still need their references rewritten
+		queue []string
+	}
+)
+
+// Bundles a schema and every external document it references into a single self-contained schema.
+// Each external document is embedded under the root "$defs" (keyed by its file name) and every "$ref"
+// is rewritten to point into the bundled schema. Documents are fetched according to the fetch options
+// given so the bundled schema can be vendored and generated from without enabling any fetching.
+func Bundle(schema []byte, opts *ParserOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &ParserOptions{}
+	}
+
+	schema, err := decodeSchemaSource(getRelativeTo(*opts), schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var root interface{}
+	if err := unmarshalPreservingNumbers(schema, &root); err != nil {
+		return nil, err
+	}
+
+	rootObject, ok := root.(map[string]interface{})
+	if !ok {
+		return schema, nil
+	}
+
+	var node schemaNode
+	if err := json.Unmarshal(schema, &node); err != nil {
+		return nil, err
+	}
+
+	resolver, err := newDocumentResolver(withDefaultParseOptions(*opts), &node)
+	if err != nil {
+		return nil, err
+	}
+
+	bundler := &schemaBundler{
+		resolver:    resolver,
+		keys:        map[string]string{},
+		definitions: map[string]interface{}{},
+	}
+
+	for documentId, document := range resolver.documents {
+		if document == &node {
+			bundler.rootDocumentIds = append(bundler.rootDocumentIds, documentId)
+		}
+	}
+
+	// Existing definitions keep their keys so references to them stay valid
+	if defs, ok := rootObject["$defs"].(map[string]interface{}); ok {
+		for key, definition := range defs {
+			bundler.definitions[key] = definition
+		}
+	}
+
+	if err := bundler.rewriteReferences(rootObject, "#", true); err != nil {
+		return nil, err
+	}
+
+	for len(bundler.queue) > 0 {
+		documentId := bundler.queue[0]
+		bundler.queue = bundler.queue[1:]
+
+		key := bundler.keys[documentId]
+		resolver.documentCurrentlyBeingParsedId = documentId
+		resolver.SetDocumentBeingResolved(documentId)
+		if err := bundler.rewriteReferences(bundler.definitions[key], "#/$defs/"+escapeJsonPointerSegment(key), false); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(bundler.definitions) > 0 {
+		rootObject["$defs"] = bundler.definitions
+	}
+
+	return json.MarshalIndent(rootObject, "", "    ")
+}
+
+// Bundles the schema in the file at the given path resolving relative "$ref"s against it (See Bundle)
+func BundleFile(path string, opts *ParserOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &ParserOptions{}
+	}
+
+	path, err := getRealPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.RelativeTo == "" {
+		opts.RelativeTo = "file://" + path
+	}
+
+	return Bundle(data, opts)
+}
+
+// Rewrites every "$ref" in the given schema (and its sub-schemas) to point into the bundled schema,
+// embedding any documents they reference that have not been embedded yet
+func (b *schemaBundler) rewriteReferences(node interface{}, pointer string, isRoot bool) error {
+	object, ok := node.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	// References are rewritten relative to the bundle root so no other base URI may be left in place
+	if !isRoot {
+		delete(object, "$id")
+		delete(object, "$schema")
+	}
+
+	if ref, ok := object["$ref"].(string); ok {
+		rewritten, err := b.rewriteReference(ref)
+		if err != nil {
+			return fmt.Errorf("failed to bundle ref [%s] at '%s': %w", ref, pointer, err)
+		}
+
+		object["$ref"] = rewritten
+	}
+
+	for _, keyword := range schemaMapKeywords {
+		schemas, ok := object[keyword].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for name, schema := range schemas {
+			if err := b.rewriteReferences(schema, fmt.Sprintf("%s/%s/%s", pointer, keyword, escapeJsonPointerSegment(name)), false); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, keyword := range subSchemaKeywords {
+		switch value := object[keyword].(type) {
+		case map[string]interface{}:
+			if err := b.rewriteReferences(value, fmt.Sprintf("%s/%s", pointer, keyword), false); err != nil {
+				return err
+			}
+		case []interface{}:
+			for i, schema := range value {
+				if err := b.rewriteReferences(schema, fmt.Sprintf("%s/%s/%d", pointer, keyword, i), false); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns the given reference rewritten to point into the bundled schema
+func (b *schemaBundler) rewriteReference(ref string) (string, error) {
+	matches := util.RegexMatchNamedCaptureGroups(documentRegex, ref)
+	if document := matches["document"]; document != "" && b.resolver.HasNoFetchers() {
+		// Without fetchers only references to known "$id"s can be resolved
+		if _, ok := b.resolver.idAliases[resolveRelativeURI(b.resolver.GetCurrentScope(), document)]; !ok {
+			return "", fmt.Errorf("document '%s' can not be fetched as no document fetchers are enabled", document)
+		}
+	}
+
+	documentId, path, err := b.resolver.ResolveDocumentIdAndPath(ref)
+	if err != nil {
+		return "", err
+	}
+
+	if path != "#" && !strings.HasPrefix(path, "#/") {
+		return "", fmt.Errorf("only JSON pointer references can be bundled, got fragment '%s'", path)
+	}
+
+	for _, rootDocumentId := range b.rootDocumentIds {
+		if documentId == rootDocumentId {
+			return path, nil
+		}
+	}
+
+	key, err := b.embedDocument(documentId)
+	if err != nil {
+		return "", err
+	}
+
+	return "#/$defs/" + escapeJsonPointerSegment(key) + strings.TrimPrefix(path, "#"), nil
+}
+
+// Embeds the document with the given ID into the root "$defs" returning the key it is embedded under
+func (b *schemaBundler) embedDocument(documentId string) (string, error) {
+	if key, ok := b.keys[documentId]; ok {
+		return key, nil
+	}
+
+	if _, ok := b.resolver.sources[documentId]; !ok {
+		if _, err := b.resolver.resolveDocument(documentId); err != nil {
+			return "", err
+		}
+	}
+
+	source, err := decodeSchemaSource(documentId, b.resolver.sources[documentId])
+	if err != nil {
+		return "", fmt.Errorf("failed to parse document '%s': %w", documentId, err)
+	}
+
+	var document interface{}
+	if err := unmarshalPreservingNumbers(source, &document); err != nil {
+		return "", fmt.Errorf("failed to parse document '%s': %w", documentId, err)
+	}
+
+	key := b.getDefinitionKey(documentId)
+	b.keys[documentId] = key
+	b.definitions[key] = document
+	b.queue = append(b.queue, documentId)
+
+	return key, nil
+}
+
+// Returns an unused key for the given document based on its file name (e.g. "address" for ".../address.json")
+func (b *schemaBundler) getDefinitionKey(documentId string) string {
+	name := documentId
+	if parsedUrl, err := url.Parse(documentId); err == nil {
+		name = parsedUrl.Path
+	}
+
+	name = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	if name == "" || name == "." || name == "/" {
+		name = "document"
+	}
+
+	key := name
+	for i := 2; ; i++ {
+		if _, exists := b.definitions[key]; !exists {
+			return key
+		}
+
+		key = fmt.Sprintf("%s_%d", name, i)
+	}
+}
+
+// Unmarshals JSON keeping numbers as they were written so bundling does not alter them
+func unmarshalPreservingNumbers(data []byte, value interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
@@ -0,0 +1,108 @@
+package chaff_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+	test "github.com/ryanolee/go-chaff/internal/test_utils"
+)
+
+var bundledRefRegex = regexp.MustCompile(`"\$ref":\s*"([^"]*)"`)
+
+// Bundles every schema matching the given pattern and checks data generated from the bundles without
+// any document fetchers is valid against them
+func testBundle(t *testing.T, pattern string, getGeneratorOptions func() *chaff.GeneratorOptions) {
+	files, err := filepath.Glob(pattern)
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Failed to list schemas matching %s: %v", pattern, err)
+	}
+
+	outputDir := t.TempDir()
+	for _, file := range files {
+		bundled, err := chaff.BundleFile(file, getDocumentChaffConfig())
+		if err != nil {
+			t.Fatalf("Failed to bundle %s: %s", file, err)
+		}
+
+		for _, match := range bundledRefRegex.FindAllStringSubmatch(string(bundled), -1) {
+			if !strings.HasPrefix(match[1], "#") {
+				t.Errorf("Expected only local references in the bundle of %s, got %s", file, match[1])
+			}
+		}
+
+		bundlePath := filepath.Join(outputDir, filepath.Base(file))
+		if err := os.WriteFile(bundlePath, bundled, 0644); err != nil {
+			t.Fatalf("Failed to write bundle: %s", err)
+		}
+
+		test.TestJsonSchema(t, bundlePath, 20, nil, getGeneratorOptions)
+	}
+}
+
+func TestBundleDocumentClusters(t *testing.T) {
+	t.Parallel()
+	testBundle(t, "test_data/document/file/cluster1_simple_refs/*.json", nil)
+	testBundle(t, "test_data/document/file/cluster5_definition_ids/*.json", nil)
+	testBundle(t, "test_data/document/file/cluster6_every_id_position/cross_doc_refs.json", nil)
+}
+
+func TestBundleCyclicDocuments(t *testing.T) {
+	t.Parallel()
+	getGeneratorOptions := func() *chaff.GeneratorOptions {
+		return &chaff.GeneratorOptions{
+			BypassCyclicReferenceCheck: true,
+			MaximumReferenceDepth:      9999,
+			MaximumGenerationSteps:     100,
+		}
+	}
+
+	testBundle(t, "test_data/document/file/cluster2_cyclic/*.json", getGeneratorOptions)
+	testBundle(t, "test_data/document/file/cluster4_deep_nested/main.json", getGeneratorOptions)
+}
+
+func TestBundleKeepsExistingDefinitions(t *testing.T) {
+	t.Parallel()
+	bundled, err := chaff.Bundle([]byte(`{
+		"$defs": {"address": {"type": "string"}},
+		"properties": {
+			"local": {"$ref": "#/$defs/address"},
+			"external": {"$ref": "test_data/document/file/cluster1_simple_refs/address.json#/properties/zipCode"}
+		}
+	}`), getDocumentChaffConfig())
+
+	if err != nil {
+		t.Fatalf("Failed to bundle schema: %s", err)
+	}
+
+	var result struct {
+		Defs       map[string]map[string]interface{} `json:"$defs"`
+		Properties map[string]struct {
+			Ref string `json:"$ref"`
+		} `json:"properties"`
+	}
+
+	if err := json.Unmarshal(bundled, &result); err != nil {
+		t.Fatalf("Failed to unmarshal bundle: %s", err)
+	}
+
+	if result.Properties["local"].Ref != "#/$defs/address" || result.Properties["external"].Ref != "#/$defs/address_2/properties/zipCode" {
+		t.Errorf("Expected references to point into the bundle, got %s", bundled)
+	}
+
+	if len(result.Defs["address"]) != 1 || result.Defs["address_2"]["title"] != "Address Schema" || result.Defs["address_2"]["$id"] != nil {
+		t.Errorf("Expected the external document to be embedded next to the existing definition, got %s", bundled)
+	}
+}
+
+func TestBundleWithoutFetchers(t *testing.T) {
+	t.Parallel()
+	_, err := chaff.Bundle([]byte(`{"properties": {"address": {"$ref": "test_data/document/file/cluster1_simple_refs/address.json"}}}`), nil)
+	if err == nil || !strings.Contains(err.Error(), "#/properties/address") {
+		t.Errorf("Expected bundling an unfetchable document to fail, got %v", err)
+	}
+}
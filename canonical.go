@@ -0,0 +1,187 @@
+package chaff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Encodes a generated value as canonical JSON following the JSON Canonicalization Scheme (RFC 8785)
+// so equal values always encode to the same bytes (e.g. for snapshot tests or content hashes).
+// Object keys are sorted by their UTF-16 code units, strings only escape what JSON requires and numbers
+// are written as ECMAScript writes doubles. Integers that can not be represented exactly as a double
+// (e.g. json.Number values beyond 2^53) are written with all of their digits rather than being rounded.
+// This deliberately deviates from RFC 8785 (which would write them as the nearest double) so generated
+// large integers are not changed by being encoded
+func MarshalCanonical(value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	if err := unmarshalPreservingNumbers(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err := writeCanonical(&buffer, decoded); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func writeCanonical(buffer *bytes.Buffer, value interface{}) error {
+	switch value := value.(type) {
+	case nil:
+		buffer.WriteString("null")
+	case bool:
+		buffer.WriteString(strconv.FormatBool(value))
+	case string:
+		writeCanonicalString(buffer, value)
+	case json.Number:
+		number, err := formatCanonicalNumber(value)
+		if err != nil {
+			return err
+		}
+
+		buffer.WriteString(number)
+	case []interface{}:
+		buffer.WriteByte('[')
+		for i, item := range value {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+
+			if err := writeCanonical(buffer, item); err != nil {
+				return err
+			}
+		}
+
+		buffer.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+
+		sort.Slice(keys, func(i, j int) bool {
+			return compareUtf16(keys[i], keys[j]) < 0
+		})
+
+		buffer.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+
+			writeCanonicalString(buffer, key)
+			buffer.WriteByte(':')
+			if err := writeCanonical(buffer, value[key]); err != nil {
+				return err
+			}
+		}
+
+		buffer.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported value of type %T for canonical json", value)
+	}
+
+	return nil
+}
+
+// Writes a string escaping only quotes, backslashes and control characters
+func writeCanonicalString(buffer *bytes.Buffer, value string) {
+	buffer.WriteByte('"')
+	for _, character := range value {
+		switch character {
+		case '"':
+			buffer.WriteString(`\"`)
+		case '\\':
+			buffer.WriteString(`\\`)
+		case '\b':
+			buffer.WriteString(`\b`)
+		case '\f':
+			buffer.WriteString(`\f`)
+		case '\n':
+			buffer.WriteString(`\n`)
+		case '\r':
+			buffer.WriteString(`\r`)
+		case '\t':
+			buffer.WriteString(`\t`)
+		default:
+			if character < 0x20 {
+				fmt.Fprintf(buffer, `\u%04x`, character)
+			} else {
+				buffer.WriteRune(character)
+			}
+		}
+	}
+
+	buffer.WriteByte('"')
+}
+
+func formatCanonicalNumber(number json.Number) (string, error) {
+	literal := number.String()
+	if !strings.ContainsAny(literal, ".eE") {
+		integer, ok := new(big.Int).SetString(literal, 10)
+		if !ok {
+			return "", fmt.Errorf("invalid number '%s'", literal)
+		}
+
+		if float, accuracy := new(big.Float).SetInt(integer).Float64(); accuracy != big.Exact || math.IsInf(float, 0) {
+			return integer.String(), nil
+		}
+	}
+
+	float, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return "", fmt.Errorf("number '%s' can not be represented in canonical json: %w", literal, err)
+	}
+
+	return formatEcmaScriptNumber(float), nil
+}
+
+// Formats a double the way ECMAScript's Number.prototype.toString does (as encoding/json does
+// other than writing negative zero as "0")
+func formatEcmaScriptNumber(float float64) string {
+	if float == 0 {
+		return "0"
+	}
+
+	format := byte('f')
+	if abs := math.Abs(float); abs < 1e-6 || abs >= 1e21 {
+		format = 'e'
+	}
+
+	formatted := strconv.FormatFloat(float, format, -1, 64)
+	if format == 'e' {
+		// Exponents are written without a leading zero (e.g. "1e-7" rather than "1e-07")
+		n := len(formatted)
+		if n >= 4 && formatted[n-4] == 'e' && formatted[n-3] == '-' && formatted[n-2] == '0' {
+			formatted = formatted[:n-2] + formatted[n-1:]
+		}
+	}
+
+	return formatted
+}
+
+// Compares two strings by their UTF-16 code units as RFC 8785 sorts object keys
+func compareUtf16(a string, b string) int {
+	aUnits := utf16.Encode([]rune(a))
+	bUnits := utf16.Encode([]rune(b))
+	for i := 0; i < len(aUnits) && i < len(bUnits); i++ {
+		if aUnits[i] != bUnits[i] {
+			return int(aUnits[i]) - int(bUnits[i])
+		}
+	}
+
+	return len(aUnits) - len(bUnits)
+}
@@ -0,0 +1,132 @@
+package chaff_test
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+func TestMarshalCanonicalNumbers(t *testing.T) {
+	t.Parallel()
+	// Test vectors from RFC 8785 Appendix B
+	testCases := map[uint64]string{
+		0x0000000000000000: "0",
+		0x8000000000000000: "0",
+		0x0000000000000001: "5e-324",
+		0x8000000000000001: "-5e-324",
+		0x7fefffffffffffff: "1.7976931348623157e+308",
+		0xffefffffffffffff: "-1.7976931348623157e+308",
+		0x4340000000000000: "9007199254740992",
+		0xc340000000000000: "-9007199254740992",
+		0x4430000000000000: "295147905179352830000",
+		0x44b52d02c7e14af5: "9.999999999999997e+22",
+		0x44b52d02c7e14af6: "1e+23",
+		0x44b52d02c7e14af7: "1.0000000000000001e+23",
+		0x444b1ae4d6e2ef4e: "999999999999999700000",
+		0x444b1ae4d6e2ef4f: "999999999999999900000",
+		0x444b1ae4d6e2ef50: "1e+21",
+		0x3eb0c6f7a0b5ed8c: "9.999999999999997e-7",
+		0x3eb0c6f7a0b5ed8d: "0.000001",
+		0x41b3de4355555553: "333333333.3333332",
+		0x41b3de4355555557: "333333333.33333343",
+		0xbecbf647612f3696: "-0.0000033333333333333333",
+		0x43143ff3c1cb0959: "1424953923781206.2",
+	}
+
+	for bits, expected := range testCases {
+		output, err := chaff.MarshalCanonical(math.Float64frombits(bits))
+		if err != nil {
+			t.Fatalf("Failed to marshal %x: %s", bits, err)
+		}
+
+		if string(output) != expected {
+			t.Errorf("Expected %x to be written as %s, got %s", bits, expected, output)
+		}
+	}
+}
+
+func TestMarshalCanonical(t *testing.T) {
+	t.Parallel()
+	value := map[string]interface{}{
+		"\u20ac":       "Euro Sign",
+		"\r":           "Carriage Return",
+		"\ufb33":       "Hebrew Letter Dalet With Dagesh",
+		"1":            "One",
+		"\U0001f600":   "Emoji: Grinning Face",
+		"\u0080":       "Control",
+		"\u00f6":       "Latin Small Letter O With Diaeresis",
+		"escapes":      "\"\\\b\f\n\r\t\u001f\u007f</>& ",
+		"integer":      json.Number("123456789012345678901234567890"),
+		"exactInteger": json.Number("1e2"),
+		"nested":       []interface{}{1, 2.5, true, nil},
+	}
+
+	output, err := chaff.MarshalCanonical(value)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %s", err)
+	}
+
+	expected := `{"\r":"Carriage Return","1":"One","escapes":"\"\\\b\f\n\r\t\u001f` + "\u007f</>& " + `","exactInteger":100,"integer":123456789012345678901234567890,"nested":[1,2.5,true,null],` +
+		"\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}"
+	if string(output) != expected {
+		t.Fatalf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+
+	formatOutput, err := chaff.MarshalOutput(value, chaff.OutputFormatCanonical)
+	if err != nil {
+		t.Fatalf("Failed to marshal output: %s", err)
+	}
+
+	if string(formatOutput) != expected {
+		t.Fatalf("Expected canonical output format to match MarshalCanonical, got %s", formatOutput)
+	}
+}
+
+func TestLargeIntegerOutput(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{
+		"type": "integer",
+		"minimum": 100000000000000000000,
+		"maximum": 200000000000000000000
+	}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		result := generator.GenerateWithDefaults()
+		number, ok := result.(json.Number)
+		if !ok {
+			t.Fatalf("Expected a json.Number, got %T", result)
+		}
+
+		integer, ok := new(big.Int).SetString(number.String(), 10)
+		if !ok {
+			t.Fatalf("Expected an integer, got %s", number)
+		}
+
+		// Most integers in range can not be represented as float64s
+		for _, format := range []chaff.OutputFormat{chaff.OutputFormatJSON, chaff.OutputFormatCanonical, chaff.OutputFormatYAML} {
+			output, err := chaff.MarshalOutput(result, format)
+			if err != nil {
+				t.Fatalf("Failed to marshal output as %s: %s", format, err)
+			}
+
+			expected := integer.String()
+			if format == chaff.OutputFormatYAML {
+				expected += "\n"
+			}
+
+			if string(output) != expected {
+				t.Fatalf("Expected %s output %s, got %s", format, expected, output)
+			}
+		}
+
+		if _, err := chaff.MarshalOutput(map[string]interface{}{"id": result}, chaff.OutputFormatTOML); err == nil {
+			t.Fatalf("Expected an error writing %s as toml", number)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+// Prints a schema with every external document it references embedded in it. Exits with 1 if the schema
+// or any of its documents could not be bundled
+func runBundle(args []string) int {
+	flags := flag.NewFlagSet("bundle", flag.ContinueOnError)
+	path := flags.String("file", "", "Specify a file path to read the JSON Schema from (JSON, JSON with comments or YAML)")
+	output := flags.String("output", "", "Specify file path to write the bundled schema to.")
+
+	fetch := addFetchFlags(flags)
+
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Prints a single self-contained schema with every external $ref document embedded under $defs\nUsage: go-chaff bundle [flags] [file]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+
+		return 1
+	}
+
+	if *path == "" && flags.NArg() > 0 {
+		*path = flags.Arg(0)
+	}
+
+	parserOptions := &chaff.ParserOptions{
+		DocumentFetchOptions: fetch.getDocumentFetchOptions(),
+	}
+
+	var bundled []byte
+	var err error
+	if *path != "" {
+		bundled, err = chaff.BundleFile(*path, parserOptions)
+	} else if hasStdin() {
+		bundled, err = chaff.Bundle(readStdin(), parserOptions)
+	} else {
+		fmt.Fprintln(os.Stderr, "no schema specified! (On Stdin, through the --file flag or as an argument)")
+		return 1
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to bundle schema: %s\n", err)
+		return 1
+	}
+
+	if *output != "" {
+		writeFile(bundled, *output)
+	} else {
+		fmt.Println(string(bundled))
+	}
+
+	return 0
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ryanolee/go-chaff"
 	"github.com/ryanolee/go-chaff/internal/util"
@@ -20,20 +21,43 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lint":
+			os.Exit(runLint(os.Args[2:]))
+		case "simplify":
+			os.Exit(runSimplify(os.Args[2:]))
+		case "bundle":
+			os.Exit(runBundle(os.Args[2:]))
+		}
+	}
+
 	// String Flags
-	path := flag.String("file", "", "Specify a file path to read the JSON Schema from")
+	path := flag.String("file", "", "Specify a file path to read the JSON Schema from (JSON, JSON with comments or YAML)")
 	output := flag.String("output", "", "Specify file path to write generated output to.")
+	trace := flag.String("trace", "", "Specify file path to write the schema node that produced each generated value to as JSON.")
+	explain := flag.String("explain", "", fmt.Sprintf("Print the compiled generator tree and $ref graph instead of generating data. (Supported: %s)", strings.Join(chaff.ExplainFormats(), ", ")))
 
 	// Fetch flags
-	allowedHosts := flag.String("allowed-hosts", "", "Comma separated list of allowed hosts to fetch remote $ref documents from over HTTP(S). If empty http and https resolution will fail.")
-	allowInsecure := flag.Bool("allow-insecure", false, "Allow fetching remote $ref documents over insecure HTTP connections.")
-	allowOutsideCwd := flag.Bool("allow-outside-cwd", false, "Allow fetching $ref documents from file system paths outside the current working directory.")
-	allowedPaths := flag.String("allowed-paths", "", "Comma separated list of allowed file system paths to fetch $ref documents from.")
+	fetch := addFetchFlags(flag.CommandLine)
+
+	// Parser flags
+	inferSemantics := flag.Bool("infer-semantics", false, "Infer realistic values for plain strings from their property names, titles and descriptions (e.g. 'email' or 'createdAt').")
+	strict := flag.Bool("strict", false, "Fail if the schema contains unknown or unsupported keywords that would be ignored during generation.")
+	validateSchema := flag.Bool("validate-schema", false, "Validate the schema and any documents it references against the meta-schema of their dialect before generating data.")
+	strictAllowedKeywords := flag.String("strict-allowed-keywords", "", "Comma separated list of additional annotation keywords to allow in strict mode.")
 
 	// Generator complexity flags
 	bypassCyclicReferenceCheck := flag.Bool("bypass-cyclic-reference-check", false, "Bypass cyclic reference check when generating schemas with cyclic $ref references.")
 	maximumReferenceDepth := flag.Int("maximum-reference-depth", 10, "Maximum depth of $ref references to resolve at once when generating data.")
 
+	// Data flags
+	now := flag.String("now", "", "RFC 3339 timestamp to use as the current time for time windows. (Default: the current time)")
+	timePast := flag.Duration("time-past", 0, "Generate 'date-time', 'date' and 'time' values no further than this duration before now (e.g. 2160h for the last 90 days).")
+	timeFuture := flag.Duration("time-future", 0, "Generate 'date-time', 'date' and 'time' values no further than this duration after now.")
+	fractionalSeconds := flag.Int("fractional-seconds", 0, "Number of fractional second digits to include in 'date-time' and 'time' values.")
+	locale := flag.String("locale", "", fmt.Sprintf("Locale to generate text, names, addresses and internationalized formats in. (Supported: %s)", strings.Join(chaff.SupportedLocales(), ", ")))
+
 	// Performance flags
 	MaximumIfAttempts := flag.Int("maximum-if-attempts", 100, "Maximum number of attempts to satisfy 'if' conditions when generating data.")
 	MaximumOneOfAttempts := flag.Int("maximum-oneof-attempts", 100, "Maximum number of attempts to satisfy 'oneOf' conditions when generating data.")
@@ -42,13 +66,15 @@ func main() {
 
 	// Bool Flags
 	formatted := flag.Bool("format", false, "Format JSON output.")
+	outputFormat := flag.String("output-format", "", fmt.Sprintf("Encoding to write generated data in. Inferred from the -output file extension if not given. (Supported: %s)", strings.Join(chaff.OutputFormats(), ", ")))
+	preservePropertyOrder := flag.Bool("preserve-property-order", false, "Write object properties in the order they are declared in the schema followed by pattern and additional properties.")
 	showHelp := flag.Bool("help", false, "Print out help.")
 	verbose := flag.Bool("verbose", false, "Print out detailed error information.")
 	showVersion := flag.Bool("version", false, "Print out cli version information.")
 	flag.Parse()
 
 	if *showHelp {
-		fmt.Println("CLI tool for generating random JSON data matching given JSON schema\nUsage: go-chaff [flags]")
+		fmt.Println("CLI tool for generating random JSON data matching given JSON schema\nUsage: go-chaff [flags]\n       go-chaff lint [flags] [file] (See go-chaff lint -help)\n       go-chaff simplify [flags] [file] (See go-chaff simplify -help)\n       go-chaff bundle [flags] [file] (See go-chaff bundle -help)")
 		flag.PrintDefaults()
 		os.Exit(0)
 	}
@@ -58,14 +84,20 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *locale != "" && !chaff.IsSupportedLocale(*locale) {
+		checkErr(fmt.Errorf("unsupported locale '%s' (Supported: %s)", *locale, strings.Join(chaff.SupportedLocales(), ", ")))
+	}
+
 	var generator chaff.RootGenerator
 	var err error
 
 	parserOptions := &chaff.ParserOptions{
-		DocumentFetchOptions: chaff.DocumentFetchOptions{
-			HTTPFetchOptions:       getHttpDocumentFetcherOptionsFromFlags(allowedHosts, allowInsecure),
-			FileSystemFetchOptions: getFileSystemDocumentFetcherOptionsFromFlags(allowOutsideCwd, allowedPaths),
-		},
+		DocumentFetchOptions:        fetch.getDocumentFetchOptions(),
+		InferStringSemantics:        *inferSemantics,
+		Strict:                      *strict,
+		StrictFailOnUnknownKeywords: *strict,
+		StrictAllowedKeywords:       parseCommaSeparatedList(strictAllowedKeywords),
+		ValidateSchema:              *validateSchema,
 	}
 
 	if *path != "" {
@@ -74,21 +106,35 @@ func main() {
 		checkErr(err)
 	} else if hasStdin() {
 		stdin := readStdin()
-		generator, err = chaff.ParseSchemaWithDefaults(stdin)
+		generator, err = chaff.ParseSchema(stdin, parserOptions)
 		checkErr(err)
 	} else {
 		checkErr(fmt.Errorf("no schema specified! (On Stdin or through the --file flag)"))
 	}
 
+	if *explain != "" {
+		var sb strings.Builder
+		checkErr(generator.Explain(&sb, chaff.ExplainFormat(*explain)))
+
+		if *output != "" {
+			writeFile([]byte(sb.String()), *output)
+		} else {
+			fmt.Print(sb.String())
+		}
+
+		os.Exit(0)
+	}
+
 	if *verbose {
 		fmt.Printf("Schema compiled successfully to the following generator tree: %s\n", generator)
 
-		if generator.Metadata.Errors.HasErrors() {
+		diagnostics := generator.Diagnostics()
+		if len(diagnostics) > 0 {
 			fmt.Println("Passed schema failed to fully compile with the following errors:")
 		}
 
-		for key, value := range generator.Metadata.Errors.CollectErrors() {
-			fmt.Printf(" - [%s] %s \n", key, value)
+		for _, diagnostic := range diagnostics {
+			fmt.Printf(" - %s\n", diagnostic)
 		}
 	}
 
@@ -99,16 +145,44 @@ func main() {
 		MaximumOneOfAttempts:       *MaximumOneOfAttempts,
 		MaximumGenerationSteps:     *MaximumGenerationSteps,
 		CutoffGenerationSteps:      *CutoffGenerationSteps,
+		Locale:                     *locale,
+		TimeWindow:                 chaff.TimeWindow{Past: *timePast, Future: *timeFuture},
+		FractionalSecondDigits:     *fractionalSeconds,
+		PreservePropertyOrder:      *preservePropertyOrder,
 	}
 
-	result := generator.Generate(generatorOptions)
-	checkErr(err)
+	if *now != "" {
+		nowTime, err := time.Parse(time.RFC3339, *now)
+		checkErr(err)
+		generatorOptions.Now = func() time.Time { return nowTime }
+	}
+
+	var result interface{}
+	if *trace != "" {
+		var valueTrace chaff.Trace
+		result, valueTrace = generator.GenerateWithTrace(generatorOptions)
+
+		traceOutput, err := json.MarshalIndent(valueTrace, "", "    ")
+		checkErr(err)
+		writeFile(traceOutput, *trace)
+	} else {
+		result = generator.Generate(generatorOptions)
+	}
+
+	format := chaff.OutputFormat(*outputFormat)
+	if inferredFormat, ok := chaff.GetOutputFormatForPath(*output); format == "" && ok {
+		format = inferredFormat
+	}
 
 	var res []byte
-	if *formatted {
-		res, err = json.MarshalIndent(result, "", "    ")
+	if format == "" || format == chaff.OutputFormatJSON {
+		if *formatted {
+			res, err = json.MarshalIndent(result, "", "    ")
+		} else {
+			res, err = json.Marshal(result)
+		}
 	} else {
-		res, err = json.Marshal(result)
+		res, err = chaff.MarshalOutput(result, format)
 	}
 
 	checkErr(err)
@@ -121,8 +195,43 @@ func main() {
 
 }
 
-func getHttpDocumentFetcherOptionsFromFlags(allowedHosts *string, allowInsecure *bool) chaff.HTTPFetchOptions {
-	if (allowedHosts != nil && *allowedHosts == "") && (allowInsecure == nil || !*allowInsecure) {
+type (
+	// Flags controlling how $ref documents are fetched (Shared by every command)
+	fetchFlags struct {
+		allowedHosts     *string
+		allowInsecure    *bool
+		allowOutsideCwd  *bool
+		allowedPaths     *string
+		cacheDir         *string
+		cacheTtl         *time.Duration
+		offline          *bool
+		fetchConcurrency *int
+	}
+)
+
+func addFetchFlags(flags *flag.FlagSet) fetchFlags {
+	return fetchFlags{
+		allowedHosts:     flags.String("allowed-hosts", "", "Comma separated list of allowed hosts to fetch remote $ref documents from over HTTP(S). If empty http and https resolution will fail."),
+		allowInsecure:    flags.Bool("allow-insecure", false, "Allow fetching remote $ref documents over insecure HTTP connections."),
+		allowOutsideCwd:  flags.Bool("allow-outside-cwd", false, "Allow fetching $ref documents from file system paths outside the current working directory."),
+		allowedPaths:     flags.String("allowed-paths", "", "Comma separated list of allowed file system paths to fetch $ref documents from."),
+		cacheDir:         flags.String("cache-dir", "", "Directory to cache remote $ref documents in. Cached documents are revalidated using their ETag / Last-Modified once older than -cache-ttl."),
+		cacheTtl:         flags.Duration("cache-ttl", 0, "How long cached remote $ref documents are used without being revalidated (e.g. 24h)."),
+		offline:          flags.Bool("offline", false, "Only serve remote $ref documents from the -cache-dir without making any requests."),
+		fetchConcurrency: flags.Int("fetch-concurrency", 1, "Maximum number of $ref documents to fetch at once while others are parsed."),
+	}
+}
+
+func (f fetchFlags) getDocumentFetchOptions() chaff.DocumentFetchOptions {
+	return chaff.DocumentFetchOptions{
+		HTTPFetchOptions:       getHttpDocumentFetcherOptionsFromFlags(f.allowedHosts, f.allowInsecure, f.cacheDir, f.cacheTtl, f.offline),
+		FileSystemFetchOptions: getFileSystemDocumentFetcherOptionsFromFlags(f.allowOutsideCwd, f.allowedPaths),
+		Concurrency:            *f.fetchConcurrency,
+	}
+}
+
+func getHttpDocumentFetcherOptionsFromFlags(allowedHosts *string, allowInsecure *bool, cacheDir *string, cacheTtl *time.Duration, offline *bool) chaff.HTTPFetchOptions {
+	if (allowedHosts != nil && *allowedHosts == "") && (allowInsecure == nil || !*allowInsecure) && (offline == nil || !*offline) {
 		return chaff.HTTPFetchOptions{}
 	}
 
@@ -130,6 +239,9 @@ func getHttpDocumentFetcherOptionsFromFlags(allowedHosts *string, allowInsecure
 		Enabled:       true,
 		AllowedHosts:  parseCommaSeparatedList(allowedHosts),
 		AllowInsecure: util.GetZeroIfNil(allowInsecure, false),
+		CacheDir:      util.GetZeroIfNil(cacheDir, ""),
+		CacheTTL:      util.GetZeroIfNil(cacheTtl, 0),
+		Offline:       util.GetZeroIfNil(offline, false),
 	}
 }
 
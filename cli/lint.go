@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+const (
+	// Exit codes of the lint command
+	lintExitOk       = 0
+	lintExitFindings = 1
+	lintExitFailure  = 2
+)
+
+// Lints a schema printing any findings. Exits with 1 if there are findings at or above the
+// "-fail-on" severity and 2 if the schema could not be read or linted at all
+func runLint(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	path := flags.String("file", "", "Specify a file path to read the JSON Schema from (JSON, JSON with comments or YAML)")
+	output := flags.String("output", "", "Specify file path to write findings to.")
+	format := flags.String("format", "text", "Format to print findings in. (Supported: text, json, sarif)")
+	failOn := flags.String("fail-on", string(chaff.DiagnosticSeverityError), "Minimum severity of findings that cause a non zero exit code. (Supported: error, unsupported, warning, none)")
+	samples := flags.Int("samples", 100, "Number of values to generate when looking for dead 'oneOf' branches and unreachable 'if' branches. (0 to disable sampling)")
+
+	fetch := addFetchFlags(flags)
+	inferSemantics := flags.Bool("infer-semantics", false, "Infer realistic values for plain strings from their property names, titles and descriptions (e.g. 'email' or 'createdAt').")
+
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Reports meta-schema violations, unsatisfiable sub schemas, contradictory allOf merges, dead oneOf branches, unreachable if branches and ignored keywords\nUsage: go-chaff lint [flags] [file]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return lintExitOk
+		}
+
+		return lintExitFailure
+	}
+
+	switch chaff.DiagnosticSeverity(*failOn) {
+	case chaff.DiagnosticSeverityError, chaff.DiagnosticSeverityUnsupported, chaff.DiagnosticSeverityWarning, "none":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown severity '%s' given for -fail-on\n", *failOn)
+		return lintExitFailure
+	}
+
+	if *path == "" && flags.NArg() > 0 {
+		*path = flags.Arg(0)
+	}
+
+	parserOptions := &chaff.ParserOptions{
+		DocumentFetchOptions: fetch.getDocumentFetchOptions(),
+		InferStringSemantics: *inferSemantics,
+		ValidateSchema:       true,
+	}
+
+	var generator chaff.RootGenerator
+	var err error
+	if *path != "" {
+		generator, err = chaff.ParseSchemaFile(*path, parserOptions)
+	} else if hasStdin() {
+		generator, err = chaff.ParseSchema(readStdin(), parserOptions)
+	} else {
+		err = fmt.Errorf("no schema specified! (On Stdin, through the --file flag or as an argument)")
+	}
+
+	// Errors from individual schema nodes are reported as findings. Only give up if nothing could be parsed
+	var validationErr *chaff.SchemaValidationError
+	var diagnostics chaff.Diagnostics
+	if errors.As(err, &validationErr) {
+		diagnostics = validationErr.Diagnostics
+	} else if err != nil && generator.Metadata == nil {
+		fmt.Fprintln(os.Stderr, err)
+		return lintExitFailure
+	} else {
+		lintSamples := *samples
+		if lintSamples == 0 {
+			lintSamples = -1
+		}
+
+		diagnostics = generator.Lint(&chaff.LintOptions{Samples: lintSamples})
+	}
+
+	var sb strings.Builder
+	if err := writeLintFindings(&sb, diagnostics, *format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return lintExitFailure
+	}
+
+	if *output != "" {
+		writeFile([]byte(sb.String()), *output)
+	} else {
+		fmt.Print(sb.String())
+	}
+
+	if *failOn != "none" && len(diagnostics.AtLeast(chaff.DiagnosticSeverity(*failOn))) > 0 {
+		return lintExitFindings
+	}
+
+	return lintExitOk
+}
+
+func writeLintFindings(w io.Writer, diagnostics chaff.Diagnostics, format string) error {
+	switch format {
+	case "text":
+		for _, diagnostic := range diagnostics {
+			if _, err := fmt.Fprintln(w, diagnostic); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case "json":
+		return diagnostics.WriteJSON(w)
+	case "sarif":
+		return diagnostics.WriteSARIF(w)
+	default:
+		return fmt.Errorf("unknown lint format '%s' (supported formats: text, json, sarif)", format)
+	}
+}
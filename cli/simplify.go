@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+// Prints the flattened, "$ref" inlined equivalent of a schema. Diagnostics are printed to stderr.
+// Exits with 1 if the schema could not be simplified at all
+func runSimplify(args []string) int {
+	flags := flag.NewFlagSet("simplify", flag.ContinueOnError)
+	path := flags.String("file", "", "Specify a file path to read the JSON Schema from (JSON, JSON with comments or YAML)")
+	output := flags.String("output", "", "Specify file path to write the simplified schema to.")
+	quiet := flags.Bool("quiet", false, "Do not print diagnostics reported while simplifying the schema.")
+
+	fetch := addFetchFlags(flags)
+
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Prints the equivalent of a schema with allOf sub-schemas merged and $refs inlined (Cyclic $refs are kept)\nUsage: go-chaff simplify [flags] [file]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+
+		return 1
+	}
+
+	if *path == "" && flags.NArg() > 0 {
+		*path = flags.Arg(0)
+	}
+
+	parserOptions := &chaff.ParserOptions{
+		DocumentFetchOptions: fetch.getDocumentFetchOptions(),
+	}
+
+	var simplified []byte
+	var diagnostics chaff.Diagnostics
+	if *path != "" {
+		simplified, diagnostics = chaff.SimplifyFile(*path, parserOptions)
+	} else if hasStdin() {
+		simplified, diagnostics = chaff.Simplify(readStdin(), parserOptions)
+	} else {
+		fmt.Fprintln(os.Stderr, "no schema specified! (On Stdin, through the --file flag or as an argument)")
+		return 1
+	}
+
+	if !*quiet || simplified == nil {
+		for _, diagnostic := range diagnostics {
+			fmt.Fprintln(os.Stderr, diagnostic)
+		}
+	}
+
+	if simplified == nil {
+		return 1
+	}
+
+	if *output != "" {
+		writeFile(simplified, *output)
+	} else {
+		fmt.Println(string(simplified))
+	}
+
+	return 0
+}
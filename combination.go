@@ -2,6 +2,7 @@ package chaff
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/ryanolee/go-chaff/internal/util"
@@ -13,6 +14,9 @@ type (
 	combinationGenerator struct {
 		Generators []Generator
 		Type       string
+
+		// Optional weights for each branch given through "x-chaff" hints
+		Weights []float64
 	}
 )
 
@@ -44,6 +48,7 @@ func parseCombination(node schemaNode, metadata *parserMetadata) (Generator, err
 	}
 
 	generators := []Generator{}
+	weights := getHintWeights(node.XChaff, len(target), metadata)
 
 	for i, subSchema := range target {
 		baseNode, _ := mergeSchemaNodes(metadata, node)
@@ -56,6 +61,13 @@ func parseCombination(node schemaNode, metadata *parserMetadata) (Generator, err
 			baseNode.AnyOf = nil
 		}
 
+		// Branch weights only apply to this node and not the branches themselves
+		if baseNode.XChaff != nil && baseNode.XChaff.Weights != nil {
+			hints := *baseNode.XChaff
+			hints.Weights = nil
+			baseNode.XChaff = &hints
+		}
+
 		mergedNode, err := mergeSchemaNodes(metadata, baseNode, subSchema)
 		if err != nil {
 			generators = append(generators, nullGenerator{})
@@ -96,6 +108,7 @@ func parseCombination(node schemaNode, metadata *parserMetadata) (Generator, err
 			internalGenerator: combinationGenerator{
 				Generators: generators,
 				Type:       nodeType,
+				Weights:    weights,
 			},
 			constraints: []constraint{oneOfConstraint},
 		}, nil
@@ -110,6 +123,7 @@ func parseCombination(node schemaNode, metadata *parserMetadata) (Generator, err
 	return combinationGenerator{
 		Generators: generators,
 		Type:       nodeType,
+		Weights:    weights,
 	}, nil
 }
 
@@ -119,8 +133,16 @@ func (g combinationGenerator) Generate(opts *GeneratorOptions) interface{} {
 		return nil
 	}
 	// Select a random generator
-	generator := g.Generators[opts.Rand.RandomInt(0, len(g.Generators))]
-	return generator.Generate(opts)
+	index := opts.Rand.RandomInt(0, len(g.Generators))
+	if g.Weights != nil {
+		index = opts.Rand.WeightedIndex(g.Weights)
+	}
+
+	if opts.trace != nil {
+		traceBranch(opts, g, index)
+	}
+
+	return g.Generators[index].Generate(opts)
 }
 
 func (g combinationGenerator) String() string {
@@ -165,13 +187,14 @@ func (oc *oneOfConstraint) Apply(generator Generator, generatorOptions *Generato
 		generatedValue = generator.Generate(generatorOptions)
 	}
 
+	traceUnsatisfied(generatorOptions)
 	return fmt.Sprintf("Failed to generate a valid value for the following oneOf constraint after %d attempts", maxAttempts)
 }
 
 func (oc *oneOfConstraint) constraintPassed(value interface{}) bool {
 	matchingSchemas := 0
 	for _, schema := range oc.schemas {
-		if schema.Validate(value) == nil {
+		if schema.Validate(unorderedValue(value)) == nil {
 			matchingSchemas++
 		}
 
@@ -189,13 +212,14 @@ func (oc *oneOfConstraint) String() string {
 }
 
 func (g constrainedGenerator) Generate(opts *GeneratorOptions) interface{} {
-	generatedValue := g.internalGenerator.Generate(opts)
+	generator := withTracedRetries(opts, g.internalGenerator)
+	generatedValue := generator.Generate(opts)
 	if opts.ShouldCutoff() {
 		return generatedValue
 	}
 	for _, constraint := range g.constraints {
 		opts.overallComplexity++
-		generatedValue = constraint.Apply(g.internalGenerator, opts, generatedValue)
+		generatedValue = constraint.Apply(generator, opts, generatedValue)
 	}
 
 	return generatedValue
@@ -207,3 +231,27 @@ func (g constrainedGenerator) String() string {
 	}).([]string)
 	return fmt.Sprintf("ConstrainedGenerator{constraints: %s, internalGenerator: %s}", strings.Join(constraintStrings, ","), g.internalGenerator)
 }
+
+func (g combinationGenerator) describe(node *GeneratorNode) {
+	node.Kind = GeneratorKind(g.Type)
+	node.Weights = g.Weights
+	for i, generator := range g.Generators {
+		node.addChild(g.Type, strconv.Itoa(i), generator)
+	}
+}
+
+func (oc *oneOfConstraint) describeConstraint(node *GeneratorNode) {
+	node.Constraints = append(node.Constraints, fmt.Sprintf("oneOf: exactly one of %d schemas", len(oc.schemas)))
+}
+
+func (g constrainedGenerator) describe(node *GeneratorNode) {
+	node.describe(g.internalGenerator)
+	for _, constraint := range g.constraints {
+		if describable, ok := constraint.(describableConstraint); ok {
+			describable.describeConstraint(node)
+			continue
+		}
+
+		node.Constraints = append(node.Constraints, constraint.String())
+	}
+}
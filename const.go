@@ -30,7 +30,7 @@ func parseConst(node schemaNode, metadata *parserMetadata) (Generator, error) {
 
 	err = subSchema.Validate(*node.Const)
 	if err != nil {
-		return nullGenerator{}, fmt.Errorf("illogical schema, const value does not match other schema constraints: %v against schema %v with error %s", util.MarshalJsonToString(node.Const), util.MarshalJsonToString(node), err.Error())
+		return nullGenerator{}, newDiagnosticError(DiagnosticSeverityError, DiagnosticCodeUnsatisfiable, fmt.Errorf("illogical schema, const value does not match other schema constraints: %v against schema %v with error %s", util.MarshalJsonToString(node.Const), util.MarshalJsonToString(node), err.Error()))
 	}
 
 	return constGenerator{
@@ -45,3 +45,8 @@ func (g constGenerator) Generate(opts *GeneratorOptions) interface{} {
 func (g constGenerator) String() string {
 	return fmt.Sprintf("ConstGenerator[%s]", util.MarshalJsonToString(g.Value))
 }
+
+func (g constGenerator) describe(node *GeneratorNode) {
+	node.Kind = GeneratorKindConst
+	node.Values = []interface{}{g.Value}
+}
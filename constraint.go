@@ -3,6 +3,7 @@ package chaff
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/ryanolee/go-chaff/internal/jsonschema"
@@ -149,7 +150,7 @@ func (mc *constraintCollection) Compile() *multiConstraint {
 
 	if len(mc.notValueConstraints) > 0 {
 		constraintFunctions[fmt.Sprintf("NotValues: %s", util.ImplodeMapStrings(mc.notValueConstraints))] = func(value interface{}) bool {
-			strValue := util.MarshalJsonToString(value)
+			strValue := util.MarshalJsonToString(unorderedValue(value))
 			_, exists := mc.notValueConstraints[strValue]
 			return !exists
 		}
@@ -157,7 +158,7 @@ func (mc *constraintCollection) Compile() *multiConstraint {
 
 	if len(mc.mustNotHaveProperties) > 0 {
 		constraintFunctions[fmt.Sprintf("MustNotHaveProperties: %s", strings.Join(mc.mustNotHaveProperties, ","))] = func(value interface{}) bool {
-			objValue, ok := value.(map[string]interface{})
+			objValue, ok := unorderedValue(value).(map[string]interface{})
 			if !ok {
 				return true
 			}
@@ -185,6 +186,7 @@ func (mc *multiConstraint) Apply(generator Generator, generatorOptions *Generato
 		generatedValue = generator.Generate(generatorOptions)
 	}
 
+	traceUnsatisfied(generatorOptions)
 	return fmt.Sprintf("Failed to generate a valid value for the following constraints {%s} after %d attempts", mc, maxAttempts)
 }
 
@@ -200,3 +202,12 @@ func (mc *multiConstraint) constraintPassed(value interface{}) bool {
 func (mc *multiConstraint) String() string {
 	return fmt.Sprintf("MultiConstraint{%s}", util.ImplodeMapStrings(mc.functions))
 }
+
+func (mc *multiConstraint) describeConstraint(node *GeneratorNode) {
+	// Constraints are compiled from "not" keywords
+	descriptions := util.MapKeysToStringSlice(&mc.functions)
+	sort.Strings(descriptions)
+	for _, description := range descriptions {
+		node.Constraints = append(node.Constraints, fmt.Sprintf("not %s", description))
+	}
+}
@@ -0,0 +1,296 @@
+package chaff
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/ryanolee/go-chaff/internal/util"
+	"github.com/ryanolee/go-chaff/rand"
+)
+
+type (
+	// Source of realistic looking values used when generating strings (formats, "x-chaff" providers and lorem text).
+	// Implementations should draw any randomness from the passed RandUtil so generation stays reproducible for a given seed.
+	// Custom providers can embed BuiltinDataProvider or FakerDataProvider and only override the methods they need.
+	DataProvider interface {
+		// Text
+		Word(r *rand.RandUtil) string
+		Sentence(r *rand.RandUtil) string
+		Paragraph(r *rand.RandUtil) string
+
+		// People
+		Name(r *rand.RandUtil) string
+		FirstName(r *rand.RandUtil) string
+		LastName(r *rand.RandUtil) string
+		Username(r *rand.RandUtil) string
+		Email(r *rand.RandUtil) string
+		Phone(r *rand.RandUtil) string
+
+		// Places
+		StreetAddress(r *rand.RandUtil) string
+		City(r *rand.RandUtil) string
+		PostalCode(r *rand.RandUtil) string
+		Country(r *rand.RandUtil) string
+
+		// Internet
+		DomainName(r *rand.RandUtil) string
+		URL(r *rand.RandUtil) string
+		IPv4(r *rand.RandUtil) string
+		IPv6(r *rand.RandUtil) string
+		UUID(r *rand.RandUtil) string
+
+		// Time
+		Timestamp(r *rand.RandUtil) time.Time
+	}
+
+	// Data provider backed by github.com/go-faker/faker. This is the default provider.
+	// N.b faker uses its own global source of randomness so values from this provider are not reproducible
+	FakerDataProvider struct{}
+
+	// Deterministic data provider that only uses the passed RandUtil and the given word lists.
+	// Any empty list falls back to a small built-in english list. Replace the lists to generate
+	// locale or domain specific values.
+	BuiltinDataProvider struct {
+		Words           []string
+		FirstNames      []string
+		LastNames       []string
+		Streets         []string
+		Cities          []string
+		Countries       []string
+		TopLevelDomains []string
+
+		// Join words and names without spaces (e.g. for Japanese or Chinese text)
+		Unspaced bool
+
+		// Put the family name before the given name
+		NameOrderReversed bool
+
+		// Punctuation ending each sentence (Default: ".")
+		SentenceTerminator string
+	}
+)
+
+var (
+	builtinWords = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+		"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+		"ad", "minim", "veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip",
+		"ex", "ea", "commodo", "consequat", "duis", "aute", "irure", "in", "reprehenderit", "voluptate",
+	}
+	builtinFirstNames = []string{
+		"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Elizabeth",
+		"William", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen",
+	}
+	builtinLastNames = []string{
+		"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+		"Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin",
+	}
+	builtinStreets = []string{
+		"Main Street", "High Street", "Park Avenue", "Oak Lane", "Maple Drive", "Cedar Road", "Elm Street", "Church Road", "Mill Lane", "Station Road",
+	}
+	builtinCities = []string{
+		"Springfield", "Riverside", "Franklin", "Greenville", "Bristol", "Clinton", "Fairview", "Salem", "Madison", "Georgetown",
+	}
+	builtinCountries = []string{
+		"United States", "United Kingdom", "Canada", "Australia", "Germany", "France", "Japan", "Brazil", "India", "South Africa",
+	}
+	builtinTopLevelDomains = []string{
+		"com", "net", "org", "io", "dev",
+	}
+)
+
+// Common latin characters that have a well known ascii spelling
+var asciiTransliterations = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss", "é", "e", "è", "e", "à", "a", "ç", "c", "ñ", "n")
+
+// The upper bound of generated timestamps (2030-01-01T00:00:00Z). Kept fixed so generation does not depend on the current time
+const builtinMaximumTimestamp = 1893456000
+
+// Faker data provider
+
+func (FakerDataProvider) Word(r *rand.RandUtil) string      { return faker.Word() }
+func (FakerDataProvider) Sentence(r *rand.RandUtil) string  { return faker.Sentence() }
+func (FakerDataProvider) Paragraph(r *rand.RandUtil) string { return faker.Paragraph() }
+func (FakerDataProvider) Name(r *rand.RandUtil) string      { return faker.Name() }
+func (FakerDataProvider) FirstName(r *rand.RandUtil) string { return faker.FirstName() }
+func (FakerDataProvider) LastName(r *rand.RandUtil) string  { return faker.LastName() }
+func (FakerDataProvider) Username(r *rand.RandUtil) string  { return faker.Username() }
+func (FakerDataProvider) Email(r *rand.RandUtil) string     { return faker.Email() }
+func (FakerDataProvider) Phone(r *rand.RandUtil) string     { return faker.E164PhoneNumber() }
+func (FakerDataProvider) StreetAddress(r *rand.RandUtil) string {
+	return faker.GetRealAddress().Address
+}
+func (FakerDataProvider) City(r *rand.RandUtil) string { return faker.GetRealAddress().City }
+func (FakerDataProvider) PostalCode(r *rand.RandUtil) string {
+	return faker.GetRealAddress().PostalCode
+}
+func (FakerDataProvider) Country(r *rand.RandUtil) string      { return faker.GetCountryInfo().Name }
+func (FakerDataProvider) DomainName(r *rand.RandUtil) string   { return faker.DomainName() }
+func (FakerDataProvider) URL(r *rand.RandUtil) string          { return faker.URL() }
+func (FakerDataProvider) IPv4(r *rand.RandUtil) string         { return faker.IPv4() }
+func (FakerDataProvider) IPv6(r *rand.RandUtil) string         { return faker.IPv6() }
+func (FakerDataProvider) UUID(r *rand.RandUtil) string         { return faker.UUIDHyphenated() }
+func (FakerDataProvider) Timestamp(r *rand.RandUtil) time.Time { return time.Unix(faker.UnixTime(), 0) }
+
+// Builtin data provider
+
+// Creates a deterministic data provider using the built-in english word lists
+func NewBuiltinDataProvider() BuiltinDataProvider {
+	return BuiltinDataProvider{}
+}
+
+func (p BuiltinDataProvider) Word(r *rand.RandUtil) string {
+	return choiceOrDefault(r, p.Words, builtinWords)
+}
+
+func (p BuiltinDataProvider) Sentence(r *rand.RandUtil) string {
+	words := make([]string, r.RandomInt(4, 11))
+	for i := range words {
+		words[i] = p.Word(r)
+	}
+
+	sentence := []rune(strings.Join(words, p.wordSeparator()))
+	sentence[0] = unicode.ToUpper(sentence[0])
+	return string(sentence) + util.GetString(p.SentenceTerminator, ".")
+}
+
+func (p BuiltinDataProvider) Paragraph(r *rand.RandUtil) string {
+	sentences := make([]string, r.RandomInt(3, 7))
+	for i := range sentences {
+		sentences[i] = p.Sentence(r)
+	}
+
+	return strings.Join(sentences, p.wordSeparator())
+}
+
+func (p BuiltinDataProvider) Name(r *rand.RandUtil) string {
+	if p.NameOrderReversed {
+		return p.LastName(r) + p.wordSeparator() + p.FirstName(r)
+	}
+
+	return p.FirstName(r) + p.wordSeparator() + p.LastName(r)
+}
+
+func (p BuiltinDataProvider) FirstName(r *rand.RandUtil) string {
+	return choiceOrDefault(r, p.FirstNames, builtinFirstNames)
+}
+
+func (p BuiltinDataProvider) LastName(r *rand.RandUtil) string {
+	return choiceOrDefault(r, p.LastNames, builtinLastNames)
+}
+
+func (p BuiltinDataProvider) Username(r *rand.RandUtil) string {
+	return fmt.Sprintf("%s%d", strings.ToLower(p.FirstName(r)), r.RandomInt(1, 1000))
+}
+
+func (p BuiltinDataProvider) Email(r *rand.RandUtil) string {
+	firstName, lastName := asciiLocalPart(p.FirstName(r)), asciiLocalPart(p.LastName(r))
+	if firstName == "" || lastName == "" {
+		return fmt.Sprintf("user%d@%s", r.RandomInt(1, 10000), p.DomainName(r))
+	}
+
+	return fmt.Sprintf("%s.%s@%s", firstName, lastName, p.DomainName(r))
+}
+
+func (p BuiltinDataProvider) Phone(r *rand.RandUtil) string {
+	return fmt.Sprintf("+1%03d%03d%04d", r.RandomInt(200, 1000), r.RandomInt(200, 1000), r.RandomInt(0, 10000))
+}
+
+func (p BuiltinDataProvider) StreetAddress(r *rand.RandUtil) string {
+	street, number := choiceOrDefault(r, p.Streets, builtinStreets), r.RandomInt(1, 1000)
+	if p.Unspaced {
+		return fmt.Sprintf("%s%d", street, number)
+	}
+
+	return fmt.Sprintf("%d %s", number, street)
+}
+
+func (p BuiltinDataProvider) City(r *rand.RandUtil) string {
+	return choiceOrDefault(r, p.Cities, builtinCities)
+}
+
+func (p BuiltinDataProvider) PostalCode(r *rand.RandUtil) string {
+	return fmt.Sprintf("%05d", r.RandomInt(0, 100000))
+}
+
+func (p BuiltinDataProvider) Country(r *rand.RandUtil) string {
+	return choiceOrDefault(r, p.Countries, builtinCountries)
+}
+
+func (p BuiltinDataProvider) DomainName(r *rand.RandUtil) string {
+	return fmt.Sprintf("%s.%s", p.asciiWord(r), choiceOrDefault(r, p.TopLevelDomains, builtinTopLevelDomains))
+}
+
+func (p BuiltinDataProvider) URL(r *rand.RandUtil) string {
+	return fmt.Sprintf("https://%s/%s", p.DomainName(r), p.asciiWord(r))
+}
+
+func (p BuiltinDataProvider) IPv4(r *rand.RandUtil) string {
+	return fmt.Sprintf("%d.%d.%d.%d", r.RandomInt(1, 256), r.RandomInt(0, 256), r.RandomInt(0, 256), r.RandomInt(0, 256))
+}
+
+func (p BuiltinDataProvider) IPv6(r *rand.RandUtil) string {
+	groups := make([]string, 8)
+	for i := range groups {
+		groups[i] = fmt.Sprintf("%x", r.RandomInt(0, 0x10000))
+	}
+
+	return strings.Join(groups, ":")
+}
+
+func (p BuiltinDataProvider) UUID(r *rand.RandUtil) string {
+	bytes := make([]byte, 16)
+	for i := range bytes {
+		bytes[i] = byte(r.RandomInt(0, 256))
+	}
+
+	// Version 4, variant 1
+	bytes[6] = (bytes[6] & 0x0f) | 0x40
+	bytes[8] = (bytes[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", bytes[0:4], bytes[4:6], bytes[6:8], bytes[8:10], bytes[10:16])
+}
+
+func (p BuiltinDataProvider) Timestamp(r *rand.RandUtil) time.Time {
+	return time.Unix(int64(r.RandomInt(0, builtinMaximumTimestamp)), 0).UTC()
+}
+
+func (p BuiltinDataProvider) wordSeparator() string {
+	if p.Unspaced {
+		return ""
+	}
+
+	return " "
+}
+
+// Returns a word that can be used in a domain name or URL, falling back to the built-in
+// words should the word list not contain any ascii letters
+func (p BuiltinDataProvider) asciiWord(r *rand.RandUtil) string {
+	if word := asciiLocalPart(p.Word(r)); word != "" {
+		return word
+	}
+
+	return r.StringChoice(&builtinWords)
+}
+
+func choiceOrDefault(r *rand.RandUtil, values []string, defaults []string) string {
+	if len(values) == 0 {
+		return r.StringChoice(&defaults)
+	}
+
+	return r.StringChoice(&values)
+}
+
+// Reduces a word to lower case ascii letters and digits so it can be used in email addresses and domains
+func asciiLocalPart(word string) string {
+	var sb strings.Builder
+	for _, r := range asciiTransliterations.Replace(strings.ToLower(word)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,108 @@
+package chaff_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+	test "github.com/ryanolee/go-chaff/internal/test_utils"
+	"github.com/ryanolee/go-chaff/rand"
+)
+
+const dataProviderTestSchema = `{
+	"type": "object",
+	"properties": {
+		"text": {"type": "string"},
+		"email": {"type": "string", "format": "email"},
+		"dateTime": {"type": "string", "format": "date-time"},
+		"uuid": {"type": "string", "format": "uuid"},
+		"ipv6": {"type": "string", "format": "ipv6"},
+		"city": {"type": "string", "x-chaff": {"faker": "city"}}
+	},
+	"required": ["text", "email", "dateTime", "uuid", "ipv6", "city"]
+}`
+
+func TestBuiltinDataProviderFormats(t *testing.T) {
+	t.Parallel()
+	test.TestJsonSchemaDirWithConfig(t, "test_data/string", 100, nil, func() *chaff.GeneratorOptions {
+		return &chaff.GeneratorOptions{
+			DataProvider: chaff.NewBuiltinDataProvider(),
+		}
+	})
+}
+
+func TestBuiltinDataProviderIsDeterministic(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(dataProviderTestSchema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	generate := func() string {
+		data, err := json.Marshal(generator.Generate(&chaff.GeneratorOptions{
+			Rand:         rand.NewRandUtil(42),
+			DataProvider: chaff.NewBuiltinDataProvider(),
+		}))
+		if err != nil {
+			t.Fatalf("Failed to marshal generated value: %s", err)
+		}
+
+		return string(data)
+	}
+
+	first := generate()
+	for i := 0; i < 10; i++ {
+		if next := generate(); next != first {
+			t.Fatalf("Expected the same output for the same seed:\n%s\n%s", first, next)
+		}
+	}
+}
+
+func TestBuiltinDataProviderWordLists(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(dataProviderTestSchema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	provider := chaff.BuiltinDataProvider{
+		Words:  []string{"chaff"},
+		Cities: []string{"Zürich"},
+	}
+
+	for i := 0; i < 20; i++ {
+		value := generator.Generate(&chaff.GeneratorOptions{DataProvider: provider}).(map[string]interface{})
+		if value["city"] != "Zürich" {
+			t.Fatalf("Expected city from the custom word list, got %v", value["city"])
+		}
+
+		for _, word := range strings.Fields(strings.ToLower(strings.ReplaceAll(value["text"].(string), ".", " "))) {
+			if word != "chaff" {
+				t.Fatalf("Expected text to only use the custom word list, got %q", value["text"])
+			}
+		}
+	}
+}
+
+// Gives empty sentences as a provider with an empty word list might
+type emptySentenceDataProvider struct {
+	chaff.BuiltinDataProvider
+}
+
+func (p emptySentenceDataProvider) Sentence(r *rand.RandUtil) string {
+	return ""
+}
+
+func TestDataProviderEmptySentences(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{"type": "string", "minLength": 5, "maxLength": 8}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	value, ok := generator.Generate(&chaff.GeneratorOptions{DataProvider: emptySentenceDataProvider{}}).(string)
+	if !ok || len(value) < 5 || len(value) > 8 {
+		t.Errorf("Expected a string padded to the minimum length, got %q", value)
+	}
+}
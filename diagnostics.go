@@ -0,0 +1,393 @@
+package chaff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type (
+	// How severe a diagnostic is
+	DiagnosticSeverity string
+
+	// Stable identifier of the kind of problem a diagnostic reports
+	DiagnosticCode string
+
+	// A problem found while parsing a schema
+	Diagnostic struct {
+		Severity DiagnosticSeverity `json:"severity"`
+		Code     DiagnosticCode     `json:"code"`
+		Message  string             `json:"message"`
+
+		// URI of the document the problem was found in
+		Document string `json:"document"`
+
+		// JSON pointer of the schema node the problem was found at (e.g. "#/properties/foo")
+		Pointer string `json:"pointer"`
+
+		// Position of the schema node in the source of the document (1 based, 0 if unknown)
+		Line   int `json:"line,omitempty"`
+		Column int `json:"column,omitempty"`
+	}
+
+	// Diagnostics of a parsed schema. Implements sort.Interface ordering by document, position and pointer
+	Diagnostics []Diagnostic
+
+	// Error carrying the severity and code it should be reported with
+	diagnosticError struct {
+		severity DiagnosticSeverity
+		code     DiagnosticCode
+		err      error
+	}
+)
+
+const (
+	// The schema node could not be compiled and a fallback generator is used in its place
+	DiagnosticSeverityError DiagnosticSeverity = "error"
+
+	// Part of the schema node was ignored or adjusted while compiling it
+	DiagnosticSeverityWarning DiagnosticSeverity = "warning"
+
+	// The schema node uses a keyword go-chaff does not support
+	DiagnosticSeverityUnsupported DiagnosticSeverity = "unsupported"
+)
+
+const (
+	DiagnosticCodeInvalidSchema       DiagnosticCode = "invalid_schema"
+	DiagnosticCodeMetaSchemaViolation DiagnosticCode = "meta_schema_violation"
+	DiagnosticCodeUnsatisfiable       DiagnosticCode = "unsatisfiable"
+	DiagnosticCodeContradictoryAllOf  DiagnosticCode = "contradictory_all_of"
+	DiagnosticCodeUnsupportedKeyword  DiagnosticCode = "unsupported_keyword"
+	DiagnosticCodeUnknownKeyword      DiagnosticCode = "unknown_keyword"
+	DiagnosticCodeKeywordIgnored      DiagnosticCode = "keyword_ignored"
+	DiagnosticCodeMergeConflict       DiagnosticCode = "merge_conflict"
+	DiagnosticCodeDocumentError       DiagnosticCode = "document_error"
+	DiagnosticCodeDeadOneOfBranch     DiagnosticCode = "dead_one_of_branch"
+	DiagnosticCodeUnreachableIfBranch DiagnosticCode = "unreachable_if_branch"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+func newDiagnosticError(severity DiagnosticSeverity, code DiagnosticCode, err error) error {
+	return diagnosticError{
+		severity: severity,
+		code:     code,
+		err:      err,
+	}
+}
+
+func (e diagnosticError) Error() string {
+	return e.err.Error()
+}
+
+func (e diagnosticError) Unwrap() error {
+	return e.err
+}
+
+// Returns the diagnostics reported while parsing the schema (and any documents it references) in sorted order
+func (g RootGenerator) Diagnostics() Diagnostics {
+	diagnostics := Diagnostics{}
+	if g.Metadata == nil || g.Metadata.Errors == nil {
+		return diagnostics
+	}
+
+	sources := map[string][]byte{}
+	if g.Metadata.DocumentResolver != nil {
+		sources = g.Metadata.DocumentResolver.sources
+	}
+
+	for document, documentErrors := range g.Metadata.Errors.Errors {
+		for path, err := range documentErrors {
+			diagnostic := newDiagnostic(document, path, err)
+			if source, ok := sources[document]; ok {
+				diagnostic.Line, diagnostic.Column = locateJsonPointer(source, diagnostic.Pointer)
+			}
+
+			diagnostics = append(diagnostics, diagnostic)
+		}
+	}
+
+	sort.Sort(diagnostics)
+	return diagnostics
+}
+
+func newDiagnostic(document string, path string, err error) Diagnostic {
+	diagnostic := Diagnostic{
+		Severity: DiagnosticSeverityError,
+		Code:     DiagnosticCodeInvalidSchema,
+		Message:  err.Error(),
+		Document: document,
+		Pointer:  path,
+	}
+
+	var classified diagnosticError
+	if errors.As(err, &classified) {
+		diagnostic.Severity = classified.severity
+		diagnostic.Code = classified.code
+	}
+
+	if !strings.HasPrefix(diagnostic.Pointer, "#") {
+		diagnostic.Pointer = "#" + diagnostic.Pointer
+	}
+
+	return diagnostic
+}
+
+func (d Diagnostic) String() string {
+	location := d.Document
+	if d.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", d.Document, d.Line, d.Column)
+	}
+
+	return fmt.Sprintf("%s: %s [%s] %s (at %s)", location, d.Severity, d.Code, d.Message, d.Pointer)
+}
+
+func (d Diagnostics) Len() int {
+	return len(d)
+}
+
+func (d Diagnostics) Less(i, j int) bool {
+	a, b := d[i], d[j]
+	if a.Document != b.Document {
+		return a.Document < b.Document
+	}
+
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+
+	if a.Column != b.Column {
+		return a.Column < b.Column
+	}
+
+	if a.Pointer != b.Pointer {
+		return a.Pointer < b.Pointer
+	}
+
+	return a.Message < b.Message
+}
+
+func (d Diagnostics) Swap(i, j int) {
+	d[i], d[j] = d[j], d[i]
+}
+
+// Returns the diagnostics with the given severity
+func (d Diagnostics) WithSeverity(severity DiagnosticSeverity) Diagnostics {
+	filtered := Diagnostics{}
+	for _, diagnostic := range d {
+		if diagnostic.Severity == severity {
+			filtered = append(filtered, diagnostic)
+		}
+	}
+
+	return filtered
+}
+
+// Returns the diagnostics at least as severe as the given severity
+// (warning < unsupported < error). Useful for deciding whether a CI check should fail
+func (d Diagnostics) AtLeast(severity DiagnosticSeverity) Diagnostics {
+	filtered := Diagnostics{}
+	for _, diagnostic := range d {
+		if diagnostic.Severity.rank() >= severity.rank() {
+			filtered = append(filtered, diagnostic)
+		}
+	}
+
+	return filtered
+}
+
+// Writes the diagnostics as a JSON array
+func (d Diagnostics) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(d)
+}
+
+// Writes the diagnostics as a SARIF 2.1.0 log (e.g. for annotating pull requests in CI)
+func (d Diagnostics) WriteSARIF(w io.Writer) error {
+	rules := []map[string]interface{}{}
+	seenRules := map[DiagnosticCode]bool{}
+	results := []map[string]interface{}{}
+
+	for _, diagnostic := range d {
+		if !seenRules[diagnostic.Code] {
+			seenRules[diagnostic.Code] = true
+			rules = append(rules, map[string]interface{}{
+				"id":               diagnostic.Code,
+				"shortDescription": map[string]string{"text": strings.ReplaceAll(string(diagnostic.Code), "_", " ")},
+			})
+		}
+
+		physicalLocation := map[string]interface{}{
+			"artifactLocation": map[string]string{"uri": diagnostic.Document},
+		}
+
+		if diagnostic.Line > 0 {
+			physicalLocation["region"] = map[string]int{
+				"startLine":   diagnostic.Line,
+				"startColumn": diagnostic.Column,
+			}
+		}
+
+		results = append(results, map[string]interface{}{
+			"ruleId":  diagnostic.Code,
+			"level":   diagnostic.Severity.sarifLevel(),
+			"message": map[string]string{"text": diagnostic.Message},
+			"locations": []map[string]interface{}{{
+				"physicalLocation": physicalLocation,
+				"logicalLocations": []map[string]string{{"fullyQualifiedName": diagnostic.Pointer}},
+			}},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(map[string]interface{}{
+		"$schema": sarifSchema,
+		"version": sarifVersion,
+		"runs": []map[string]interface{}{{
+			"tool": map[string]interface{}{
+				"driver": map[string]interface{}{
+					"name":           "go-chaff",
+					"informationUri": "https://github.com/ryanolee/go-chaff",
+					"rules":          rules,
+				},
+			},
+			"results": results,
+		}},
+	})
+}
+
+func (s DiagnosticSeverity) rank() int {
+	switch s {
+	case DiagnosticSeverityWarning:
+		return 1
+	case DiagnosticSeverityUnsupported:
+		return 2
+	case DiagnosticSeverityError:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func (s DiagnosticSeverity) sarifLevel() string {
+	switch s {
+	case DiagnosticSeverityError:
+		return "error"
+	case DiagnosticSeverityWarning, DiagnosticSeverityUnsupported:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// Finds the line and column of the value at the given JSON pointer within a JSON (or YAML) document.
+// Pointers to locations that do not exist in the source (e.g. "#/if/0/config_compile_error")
+// resolve to the deepest value that does. Returns 0, 0 if the source can not be read
+func locateJsonPointer(source []byte, pointer string) (int, int) {
+	segments := []string{}
+	for _, segment := range strings.Split(strings.TrimPrefix(pointer, "#"), "/") {
+		if segment != "" {
+			segments = append(segments, unescapeJsonPointerSegment(segment))
+		}
+	}
+
+	// Comments are replaced with whitespace so they do not shift the location of anything else
+	if !json.Valid(source) {
+		stripped := stripJsonComments(source)
+		if !json.Valid(stripped) {
+			if line, column, ok := locateYamlPointer(source, segments); ok {
+				return line, column
+			}
+		}
+
+		source = stripped
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(source))
+	offset, ok := findJsonPointerOffset(decoder, source, segments)
+	if !ok {
+		return 0, 0
+	}
+
+	line, column := 1, 1
+	for _, char := range string(source[:offset]) {
+		if char == '\n' {
+			line++
+			column = 1
+			continue
+		}
+
+		column++
+	}
+
+	return line, column
+}
+
+// Returns the offset of the deepest value along the given path. The decoder must be positioned before a value
+func findJsonPointerOffset(decoder *json.Decoder, source []byte, segments []string) (int64, bool) {
+	offset := skipJsonSeparators(source, decoder.InputOffset())
+	if len(segments) == 0 {
+		return offset, offset < int64(len(source))
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return 0, false
+	}
+
+	delim, isDelim := token.(json.Delim)
+	if !isDelim || (delim != '{' && delim != '[') {
+		return offset, true
+	}
+
+	for index := 0; decoder.More(); index++ {
+		key := strconv.Itoa(index)
+		if delim == '{' {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return offset, true
+			}
+
+			key, _ = keyToken.(string)
+		}
+
+		if key == segments[0] {
+			if childOffset, ok := findJsonPointerOffset(decoder, source, segments[1:]); ok {
+				return childOffset, true
+			}
+
+			return offset, true
+		}
+
+		if err := decoder.Decode(&json.RawMessage{}); err != nil {
+			return offset, true
+		}
+	}
+
+	return offset, true
+}
+
+// Skips whitespace and the separators between JSON tokens
+func skipJsonSeparators(source []byte, offset int64) int64 {
+	for offset < int64(len(source)) {
+		char, size := utf8.DecodeRune(source[offset:])
+		if !strings.ContainsRune(" \t\r\n:,", char) {
+			break
+		}
+
+		offset += int64(size)
+	}
+
+	return offset
+}
@@ -0,0 +1,196 @@
+package chaff_test
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+const diagnosticsTestSchema = `{
+	"type": "object",
+	"properties": {
+		"pattern": {"type": "string", "pattern": "(["},
+		"dependent": {"type": "object", "dependentRequired": {"a": ["b"]}}
+	}
+}`
+
+func getDiagnosticsTestGenerator(t *testing.T) chaff.RootGenerator {
+	generator, err := chaff.ParseSchemaStringWithDefaults(diagnosticsTestSchema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	return generator
+}
+
+func TestDiagnostics(t *testing.T) {
+	t.Parallel()
+	diagnostics := getDiagnosticsTestGenerator(t).Diagnostics()
+	if len(diagnostics) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %d: %v", len(diagnostics), diagnostics)
+	}
+
+	expected := chaff.Diagnostics{
+		{Severity: chaff.DiagnosticSeverityError, Code: chaff.DiagnosticCodeInvalidSchema, Pointer: "#/properties/pattern", Line: 4, Column: 14},
+		{Severity: chaff.DiagnosticSeverityUnsupported, Code: chaff.DiagnosticCodeUnsupportedKeyword, Pointer: "#/properties/dependent", Line: 5, Column: 16},
+	}
+
+	for i, diagnostic := range diagnostics {
+		if diagnostic.Severity != expected[i].Severity || diagnostic.Code != expected[i].Code || diagnostic.Pointer != expected[i].Pointer {
+			t.Errorf("Expected diagnostic %d to be %+v, got %+v", i, expected[i], diagnostic)
+		}
+
+		if diagnostic.Line != expected[i].Line || diagnostic.Column != expected[i].Column {
+			t.Errorf("Expected diagnostic %d at %d:%d, got %d:%d", i, expected[i].Line, expected[i].Column, diagnostic.Line, diagnostic.Column)
+		}
+
+		if diagnostic.Document == "" || diagnostic.Message == "" {
+			t.Errorf("Expected diagnostic %d to have a document and message, got %+v", i, diagnostic)
+		}
+	}
+
+	if !sort.IsSorted(diagnostics) {
+		t.Errorf("Expected diagnostics to be sorted")
+	}
+
+	if len(diagnostics.WithSeverity(chaff.DiagnosticSeverityError)) != 1 {
+		t.Errorf("Expected exactly one error diagnostic")
+	}
+}
+
+func TestDiagnosticsCleanSchema(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{"type": "string"}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	if diagnostics := generator.Diagnostics(); len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", diagnostics)
+	}
+}
+
+func TestDiagnosticsExternalDocument(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaString(`{"$ref": "test_data/diagnostics/external.json"}`, getDocumentDiagnosticsConfig())
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	diagnostics := generator.Diagnostics()
+	if len(diagnostics) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %d: %v", len(diagnostics), diagnostics)
+	}
+
+	diagnostic := diagnostics[0]
+	if !strings.HasSuffix(diagnostic.Document, "test_data/diagnostics/external.json") || diagnostic.Pointer != "#/properties/name" {
+		t.Errorf("Expected the diagnostic to point into the external document, got %+v", diagnostic)
+	}
+
+	if diagnostic.Line != 4 || diagnostic.Column != 17 {
+		t.Errorf("Expected the diagnostic at 4:17, got %d:%d", diagnostic.Line, diagnostic.Column)
+	}
+}
+
+func TestDiagnosticsJson(t *testing.T) {
+	t.Parallel()
+	var sb strings.Builder
+	if err := getDiagnosticsTestGenerator(t).Diagnostics().WriteJSON(&sb); err != nil {
+		t.Fatalf("Failed to write diagnostics: %s", err)
+	}
+
+	var diagnostics chaff.Diagnostics
+	if err := json.Unmarshal([]byte(sb.String()), &diagnostics); err != nil {
+		t.Fatalf("Failed to unmarshal diagnostics: %s", err)
+	}
+
+	if len(diagnostics) != 2 || diagnostics[0].Pointer != "#/properties/pattern" {
+		t.Errorf("Expected diagnostics to round trip, got %+v", diagnostics)
+	}
+}
+
+func TestDiagnosticsSarif(t *testing.T) {
+	t.Parallel()
+	var sb strings.Builder
+	if err := getDiagnosticsTestGenerator(t).Diagnostics().WriteSARIF(&sb); err != nil {
+		t.Fatalf("Failed to write diagnostics: %s", err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleId    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+
+	if err := json.Unmarshal([]byte(sb.String()), &log); err != nil {
+		t.Fatalf("Failed to unmarshal SARIF log: %s", err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 2 {
+		t.Fatalf("Unexpected SARIF log: %s", sb.String())
+	}
+
+	result := log.Runs[0].Results[0]
+	if result.RuleId != string(chaff.DiagnosticCodeInvalidSchema) || result.Level != "error" || result.Locations[0].PhysicalLocation.Region.StartLine != 4 {
+		t.Errorf("Unexpected SARIF result: %+v", result)
+	}
+}
+
+func getDocumentDiagnosticsConfig() *chaff.ParserOptions {
+	return &chaff.ParserOptions{
+		DocumentFetchOptions: chaff.DocumentFetchOptions{
+			FileSystemFetchOptions: chaff.FileSystemFetchOptions{
+				Enabled:      true,
+				AllowedPaths: []string{"test_data"},
+			},
+		},
+	}
+}
+
+func TestDiagnosticsInvalidDocument(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaString(`{"$ref": "test_data/diagnostics/invalid_document.json"}`, getDocumentDiagnosticsConfig())
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	diagnostics := generator.Diagnostics()
+	if len(diagnostics) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %d: %v", len(diagnostics), diagnostics)
+	}
+
+	if diagnostics[0].Code != chaff.DiagnosticCodeDocumentError || diagnostics[0].Pointer != "#/document_parse_error" {
+		t.Errorf("Expected a document error at #/document_parse_error, got %+v", diagnostics[0])
+	}
+}
+
+func TestDiagnosticsUnsupportedNotKeyword(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{"type": "string", "not": {"anyOf": [{"const": "a"}, {"const": "b"}]}}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	diagnostics := generator.Diagnostics().AtLeast(chaff.DiagnosticSeverityUnsupported)
+	if len(diagnostics) != 1 {
+		t.Fatalf("Expected 1 unsupported diagnostic, got %v", generator.Diagnostics())
+	}
+
+	if diagnostics[0].Severity != chaff.DiagnosticSeverityUnsupported || diagnostics[0].Code != chaff.DiagnosticCodeUnsupportedKeyword || diagnostics[0].Pointer != "#/not/anyOf" {
+		t.Errorf("Expected an unsupported_keyword diagnostic at #/not/anyOf, got %+v", diagnostics[0])
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/ryanolee/go-chaff/internal/util"
@@ -25,11 +26,23 @@ type (
 		documentCurrentlyBeingResolvedId string
 
 		// A map of protocols to their associated document fetchers
-		documentFetchers map[string]*documentFetcherInterface
+		documentFetchers map[string]DocumentFetcher
 
 		// Maps resolved $id URIs to the real document + JSON pointer path
 		// where the sub-schema lives, avoiding document duplication.
 		idAliases map[string]idAlias
+
+		// Raw source of each document (Used to report line and column numbers in diagnostics)
+		sources map[string][]byte
+
+		// If fetched documents should be validated against their meta-schema before being parsed
+		validateSchemas bool
+
+		// Documents that failed meta-schema validation
+		schemaValidationErrors []*SchemaValidationError
+
+		// Fetches queued documents in the background (nil if documents are fetched as they are parsed)
+		prefetcher *documentPrefetcher
 	}
 
 	// idAlias maps a resolved $id URI back to the parent document and the
@@ -41,11 +54,6 @@ type (
 		path       string // JSON pointer within documentId, e.g. "#/$defs/color"
 	}
 
-	documentFetcherInterface interface {
-		fetchDocument(ref string) (*schemaNode, error)
-		resolveDocumentId(relativeTo string, ref string) (string, error)
-	}
-
 	// Used for rewriting references simply
 	genericNode map[string]interface{}
 )
@@ -56,14 +64,14 @@ var documentRegex = regexp.MustCompile("^(?P<document>(?:[a-zA-Z][a-zA-Z0-9+.-]*
 const rootDocumentId = "8dabc98a-527b-4f08-baba-315beb368097.json"
 
 func newDocumentResolver(opts ParserOptions, rootDocument *schemaNode) (*documentResolver, error) {
-	documentFetchers := make(map[string]*documentFetcherInterface)
+	documentFetchers := make(map[string]DocumentFetcher)
 	if opts.DocumentFetchOptions.HTTPFetchOptions.Enabled {
 		httpFetcher, err := NewHttpDocumentFetcher(opts.DocumentFetchOptions.HTTPFetchOptions)
 		if err != nil {
 			return nil, err
 		}
-		documentFetchers["http"] = &httpFetcher
-		documentFetchers["https"] = &httpFetcher
+		documentFetchers["http"] = httpFetcher
+		documentFetchers["https"] = httpFetcher
 	}
 
 	if opts.DocumentFetchOptions.FileSystemFetchOptions.Enabled {
@@ -71,20 +79,24 @@ func newDocumentResolver(opts ParserOptions, rootDocument *schemaNode) (*documen
 		if err != nil {
 			return nil, err
 		}
-		documentFetchers["file"] = &fsFetcher
+		documentFetchers["file"] = fsFetcher
+	}
+
+	// Custom fetchers take precedence over the built in ones for the same scheme
+	for scheme, fetcher := range opts.DocumentFetchOptions.Fetchers {
+		if fetcher == nil {
+			delete(documentFetchers, scheme)
+			continue
+		}
+
+		documentFetchers[scheme] = fetcher
 	}
 
 	// Set relative to to current working directory if not set
 	resolvedRootDocumentId := rootDocumentId
 
 	if opts.RelativeTo == "" {
-		cwd, err := os.Getwd()
-		if err != nil {
-			opts.RelativeTo = "file://./"
-		} else {
-			opts.RelativeTo = "file://" + cwd + "/"
-		}
-
+		opts.RelativeTo = getRelativeTo(opts)
 		resolvedRootDocumentId = filepath.Join(opts.RelativeTo, rootDocumentId)
 	} else {
 		resolvedRootDocumentId = opts.RelativeTo
@@ -101,8 +113,16 @@ func newDocumentResolver(opts ParserOptions, rootDocument *schemaNode) (*documen
 		},
 		documentFetchers: documentFetchers,
 		idAliases:        make(map[string]idAlias),
+		sources:          make(map[string][]byte),
+		validateSchemas:  opts.ValidateSchema,
+		prefetcher:       newDocumentPrefetcher(opts.DocumentFetchOptions.Concurrency),
 	}
 
+	// Local references are resolved against the scope of the document being parsed
+	// which for the root document is the path it is relative to
+	resolver.documents[opts.RelativeTo] = rootDocument
+	resolver.parsedExternalDocuments[opts.RelativeTo] = true
+
 	// Collect $id aliases from the root document tree so that relative
 	// $ref values (e.g. $ref: "color") can be resolved without I/O.
 	baseURI := resolvedRootDocumentId
@@ -115,9 +135,74 @@ func newDocumentResolver(opts ParserOptions, rootDocument *schemaNode) (*documen
 		resolver.idAliases[resolvedId] = alias
 	}
 
+	if err := resolver.addPreloadedDocuments(opts.RelativeTo, opts.Documents); err != nil {
+		return nil, err
+	}
+
 	return resolver, nil
 }
 
+// Registers documents given up front so references to them resolve without any fetching (See ParserOptions.Documents)
+func (r *documentResolver) addPreloadedDocuments(relativeTo string, documents map[string][]byte) error {
+	ids := make([]string, 0, len(documents))
+	for id := range documents {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	for _, id := range ids {
+		source := documents[id]
+		documentId := resolveRelativeURI(relativeTo, id)
+		if documentId == "" {
+			return fmt.Errorf("invalid document URI '%s'", id)
+		}
+
+		if r.validateSchemas {
+			var validationErr *SchemaValidationError
+			if errors.As(validateSchemaSource(documentId, source), &validationErr) {
+				r.schemaValidationErrors = append(r.schemaValidationErrors, validationErr)
+			}
+		}
+
+		data, err := decodeSchemaSource(documentId, source)
+		if err != nil {
+			return fmt.Errorf("failed to parse schema json from document '%s': %w", documentId, err)
+		}
+
+		document := &schemaNode{}
+		if err := json.Unmarshal(data, document); err != nil {
+			return fmt.Errorf("failed to parse schema json from document '%s': %w", documentId, err)
+		}
+
+		r.sources[documentId] = source
+		r.addDocument(documentId, document)
+
+		// Preloaded documents resolve like "$id"s so no fetcher is needed for them
+		r.idAliases[documentId] = idAlias{documentId: documentId, path: "#"}
+		if document.Id != nil {
+			if resolvedId := resolveRelativeURI(documentId, *document.Id); resolvedId != "" && resolvedId != documentId {
+				r.idAliases[resolvedId] = idAlias{documentId: documentId, path: "#"}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns the base path documents are resolved relative to (Defaults to the current working directory)
+func getRelativeTo(opts ParserOptions) string {
+	if opts.RelativeTo != "" {
+		return opts.RelativeTo
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "file://./"
+	}
+
+	return "file://" + cwd + "/"
+}
+
 func (r *documentResolver) GetDocumentIdCurrentlyBeingParsed() string {
 	return r.documentCurrentlyBeingParsedId
 }
@@ -254,6 +339,7 @@ func (r *documentResolver) HandleDeferredReferenceResolution(ref string, metadat
 	// Queue document for parsing if it hasn't already been parsed
 	if !funk.ContainsString(r.externalDocumentsThatNeedParsing, resolvedDocId) && r.pathWouldRequireNewDocumentParsed(resolvedDocId) {
 		r.externalDocumentsThatNeedParsing = append(r.externalDocumentsThatNeedParsing, resolvedDocId)
+		r.prefetchDocument(resolvedDocId)
 	}
 
 	return resolvedDocId, resolvedPath, nil
@@ -432,9 +518,13 @@ func (r *documentResolver) ParseNextDocument(metadata *parserMetadata) (string,
 	// Resolve the document
 	r.documentCurrentlyBeingParsedId = nextDocumentId
 	r.documentCurrentlyBeingResolvedId = nextDocumentId
-	documentNode, err := r.resolveDocument(nextDocumentId)
-	if err != nil {
-		return nextDocumentId, fmt.Errorf("failed to resolve document '%s': %w", nextDocumentId, err)
+	var err error
+	documentNode, ok := r.documents[nextDocumentId]
+	if !ok {
+		documentNode, err = r.resolveDocument(nextDocumentId)
+		if err != nil {
+			return nextDocumentId, fmt.Errorf("failed to resolve document '%s': %w", nextDocumentId, err)
+		}
 	}
 
 	// Mark as parsed before parseRoot so that intra-document $id-based
@@ -455,26 +545,82 @@ func (r *documentResolver) resolveDocument(ref string) (*schemaNode, error) {
 	matches := util.RegexMatchNamedCaptureGroups(documentRegex, ref)
 	documentID, hasDocument := matches["document"]
 
-	if !hasDocument || documentID == "" || r.HasNoFetchers() {
+	if !hasDocument || documentID == "" {
 		return nil, fmt.Errorf("invalid $ref format, must contain document, ref given '%s'", ref)
 	}
 
+	if r.HasNoFetchers() {
+		return nil, fmt.Errorf("document '%s' can not be fetched as no document fetchers are enabled and it was not given in ParserOptions.Documents", documentID)
+	}
+
 	fetcher, err := r.getFetcherForRef(documentID)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get document fetcher for document '%s': %w", ref, err)
 	}
 
-	document, err := fetcher.fetchDocument(documentID)
+	source, err := r.fetchDocument(fetcher, documentID)
+
+	// Validate before reporting parse errors as those are far less readable for malformed schemas
+	if source != nil && r.validateSchemas {
+		r.sources[documentID] = source
+
+		var validationErr *SchemaValidationError
+		if errors.As(validateSchemaSource(documentID, source), &validationErr) {
+			r.schemaValidationErrors = append(r.schemaValidationErrors, validationErr)
+			return nil, fmt.Errorf("failed to validate document '%s': %w", ref, validationErr)
+		}
+	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch document '%s': %w", ref, err)
 	}
 
+	r.sources[documentID] = source
+	data, err := decodeSchemaSource(documentID, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema json from document '%s': %w", documentID, err)
+	}
+
+	document := &schemaNode{}
+	if err := json.Unmarshal(data, document); err != nil {
+		return nil, fmt.Errorf("failed to parse schema json from document '%s': %w", documentID, err)
+	}
+
 	r.addDocument(documentID, document)
 	return document, nil
 }
 
+// Starts fetching a queued document in the background if prefetching is enabled. Documents that
+// can not be fetched yet are left to be fetched (and have their errors reported) once they are parsed
+func (r *documentResolver) prefetchDocument(documentId string) {
+	if r.prefetcher == nil {
+		return
+	}
+
+	if _, loaded := r.documents[documentId]; loaded {
+		return
+	}
+
+	fetcher, err := r.getFetcherForRef(documentId)
+	if err != nil {
+		return
+	}
+
+	r.prefetcher.prefetch(documentId, fetcher)
+}
+
+// Fetches the source of a document, waiting for it to be prefetched if it already has been queued
+func (r *documentResolver) fetchDocument(fetcher DocumentFetcher, documentId string) ([]byte, error) {
+	if r.prefetcher != nil {
+		if document := r.prefetcher.wait(documentId); document != nil {
+			return document.source, document.err
+		}
+	}
+
+	return fetcher.FetchDocument(documentId)
+}
+
 // resolveDocumentRef resolves a document reference to its canonical document
 // ID and path. It checks the $id alias table first (a lightweight map of
 // resolved $id URI → document + JSON pointer), falling back to I/O-based
@@ -490,8 +636,13 @@ func (r *documentResolver) resolveDocumentRef(documentID string, refPath string)
 	}
 
 	// No alias found — fall back to I/O-based fetchers.
+	// Without any the document is still queued so it is reported as one that can not be fetched
 	if r.HasNoFetchers() {
-		return r.GetCurrentScope(), refPath, nil
+		if resolved == "" {
+			resolved = documentID
+		}
+
+		return resolved, refPath, nil
 	}
 
 	fetcher, err := r.getFetcherForRef(documentID)
@@ -499,7 +650,7 @@ func (r *documentResolver) resolveDocumentRef(documentID string, refPath string)
 		return "", "", fmt.Errorf("failed to get document fetcher for document '%s': %w", documentID, err)
 	}
 
-	resolvedDocumentId, err := fetcher.resolveDocumentId(r.GetCurrentScope(), documentID)
+	resolvedDocumentId, err := fetcher.ResolveDocumentId(r.GetCurrentScope(), documentID)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to resolve document ID for reference '%s': %w", documentID, err)
 	}
@@ -518,7 +669,7 @@ func composeJsonPointerPaths(aliasPath string, refPath string) string {
 	return aliasPath + refPath[1:]
 }
 
-func (r *documentResolver) getFetcherForRef(documentId string) (documentFetcherInterface, error) {
+func (r *documentResolver) getFetcherForRef(documentId string) (DocumentFetcher, error) {
 	url, err := url.Parse(documentId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse document URL '%s': %w", err, err)
@@ -537,9 +688,9 @@ func (r *documentResolver) getFetcherForRef(documentId string) (documentFetcherI
 	}
 
 	fetcher, ok := r.documentFetchers[url.Scheme]
-	if !ok || fetcher == nil {
-		return nil, fmt.Errorf("no document fetcher registered for protocol check to see if you allow the '%s://' scheme the currently supported schemes if enabled are ('file://', 'http://', 'https://')", url.Scheme)
+	if !ok {
+		return nil, fmt.Errorf("no document fetcher registered for protocol check to see if you allow the '%s://' scheme the currently supported schemes if enabled are ('file://', 'http://', 'https://') along with any given in DocumentFetchOptions.Fetchers", url.Scheme)
 	}
 
-	return *fetcher, nil
+	return fetcher, nil
 }
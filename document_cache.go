@@ -0,0 +1,111 @@
+package chaff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type (
+	// Caches documents fetched over HTTP in a directory keyed by their URL (See HTTPFetchOptions.CacheDir)
+	documentCache struct {
+		dir string
+
+		// How long a cached document is used without being revalidated
+		ttl time.Duration
+	}
+
+	// Stored next to each cached document to revalidate it with the server
+	documentCacheEntry struct {
+		Url          string    `json:"url"`
+		ETag         string    `json:"etag,omitempty"`
+		LastModified string    `json:"lastModified,omitempty"`
+		FetchedAt    time.Time `json:"fetchedAt"`
+	}
+)
+
+func newDocumentCache(dir string, ttl time.Duration) (*documentCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create document cache directory '%s': %w", dir, err)
+	}
+
+	return &documentCache{
+		dir: dir,
+		ttl: ttl,
+	}, nil
+}
+
+// Returns the cached document for the given URL along with its entry (nil if it is not cached)
+func (c *documentCache) load(url string) (*documentCacheEntry, []byte) {
+	documentPath, entryPath := c.getPaths(url)
+	entryData, err := os.ReadFile(entryPath)
+	if err != nil {
+		return nil, nil
+	}
+
+	entry := &documentCacheEntry{}
+	if err := json.Unmarshal(entryData, entry); err != nil || entry.Url != url {
+		return nil, nil
+	}
+
+	document, err := os.ReadFile(documentPath)
+	if err != nil {
+		return nil, nil
+	}
+
+	return entry, document
+}
+
+// Caches the document for the given URL. The entry is written last so partially written documents are never used
+func (c *documentCache) store(entry documentCacheEntry, document []byte) error {
+	documentPath, entryPath := c.getPaths(entry.Url)
+	entryData, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomically(documentPath, document); err != nil {
+		return fmt.Errorf("failed to cache document '%s': %w", entry.Url, err)
+	}
+
+	if err := writeFileAtomically(entryPath, entryData); err != nil {
+		return fmt.Errorf("failed to cache document '%s': %w", entry.Url, err)
+	}
+
+	return nil
+}
+
+// If the cached document can be used without revalidating it
+func (c *documentCache) isFresh(entry *documentCacheEntry) bool {
+	return time.Since(entry.FetchedAt) < c.ttl
+}
+
+func (c *documentCache) getPaths(url string) (string, string) {
+	sum := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, key+".json"), filepath.Join(c.dir, key+".meta.json")
+}
+
+func writeFileAtomically(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
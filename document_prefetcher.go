@@ -0,0 +1,77 @@
+package chaff
+
+type (
+	// Fetches queued documents in the background so parsing does not wait on each fetch in turn.
+	// Only the fetching happens concurrently, documents are still validated and parsed one at a time
+	// in the order they were queued so parsing and error attribution stay deterministic
+	documentPrefetcher struct {
+		// Limits the number of fetches in flight at once
+		slots chan struct{}
+
+		// Documents that are being (or have been) fetched by their ID
+		documents map[string]*prefetchedDocument
+	}
+
+	// Implemented by fetchers limiting how many documents they fetch (e.g. HTTPFetchOptions.MaxDocuments). Documents
+	// are reserved as they are queued so which of them exceed the limit does not depend on the order their fetches start in
+	documentReserver interface {
+		reserveDocument(documentId string) error
+	}
+
+	prefetchedDocument struct {
+		// Closed once the document has been fetched
+		done chan struct{}
+
+		source []byte
+		err    error
+	}
+)
+
+// Returns a prefetcher fetching up to the given number of documents at once (nil if documents should
+// only be fetched as they are parsed)
+func newDocumentPrefetcher(concurrency int) *documentPrefetcher {
+	if concurrency <= 1 {
+		return nil
+	}
+
+	return &documentPrefetcher{
+		slots:     make(chan struct{}, concurrency),
+		documents: map[string]*prefetchedDocument{},
+	}
+}
+
+// Starts fetching the given document in the background unless it is already being fetched
+func (p *documentPrefetcher) prefetch(documentId string, fetcher DocumentFetcher) {
+	if _, ok := p.documents[documentId]; ok {
+		return
+	}
+
+	document := &prefetchedDocument{done: make(chan struct{})}
+	p.documents[documentId] = document
+
+	if reserver, ok := fetcher.(documentReserver); ok {
+		if document.err = reserver.reserveDocument(documentId); document.err != nil {
+			close(document.done)
+			return
+		}
+	}
+
+	go func() {
+		p.slots <- struct{}{}
+		defer func() { <-p.slots }()
+		defer close(document.done)
+
+		document.source, document.err = fetcher.FetchDocument(documentId)
+	}()
+}
+
+// Waits for the given document to be fetched (nil if it was never prefetched)
+func (p *documentPrefetcher) wait(documentId string) *prefetchedDocument {
+	document, ok := p.documents[documentId]
+	if !ok {
+		return nil
+	}
+
+	<-document.done
+	return document
+}
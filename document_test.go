@@ -1,6 +1,7 @@
 package chaff_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/ryanolee/go-chaff"
@@ -92,3 +93,67 @@ func TestHttpDocuments(t *testing.T) {
 		}
 	})
 }
+
+func getPreloadedDocumentsChaffConfig() *chaff.ParserOptions {
+	return &chaff.ParserOptions{
+		Documents: map[string][]byte{
+			"customer.json": []byte(`{
+				"type": "object",
+				"properties": {"address": {"$ref": "https://schemas.example.com/address.json#/$defs/street"}},
+				"required": ["address"]
+			}`),
+			"address.json": []byte(`{
+				"$id": "https://schemas.example.com/address.json",
+				"$defs": {"street": {"const": "1 Main Street"}}
+			}`),
+		},
+	}
+}
+
+func TestPreloadedDocuments(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaString(`{
+		"type": "object",
+		"properties": {"customer": {"$ref": "customer.json"}},
+		"required": ["customer"]
+	}`, getPreloadedDocumentsChaffConfig())
+
+	if err != nil || generator.Metadata.Errors.HasErrors() {
+		t.Fatalf("Failed to parse schema: %v %v", err, generator.Metadata.Errors.CollectErrors())
+	}
+
+	result := generator.GenerateWithDefaults().(map[string]interface{})
+	customer, ok := result["customer"].(map[string]interface{})
+	if !ok || customer["address"] != "1 Main Street" {
+		t.Errorf("Expected the preloaded documents to be resolved without fetching, got %v", result)
+	}
+}
+
+func TestPreloadedDocumentsMissing(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaString(`{"$ref": "missing.json"}`, getPreloadedDocumentsChaffConfig())
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	found := false
+	for key, err := range generator.Metadata.Errors.CollectErrors() {
+		found = found || (strings.Contains(key, "missing.json") && strings.Contains(err.Error(), "no document fetchers are enabled"))
+	}
+
+	if !found {
+		t.Errorf("Expected an error for the document that is neither preloaded nor fetchable, got %v", generator.Metadata.Errors.CollectErrors())
+	}
+}
+
+func TestPreloadedDocumentsBundle(t *testing.T) {
+	t.Parallel()
+	bundled, err := chaff.Bundle([]byte(`{"$ref": "customer.json"}`), getPreloadedDocumentsChaffConfig())
+	if err != nil {
+		t.Fatalf("Failed to bundle schema: %s", err)
+	}
+
+	if !strings.Contains(string(bundled), `"$ref": "#/$defs/address/$defs/street"`) {
+		t.Errorf("Expected the preloaded documents to be embedded, got %s", bundled)
+	}
+}
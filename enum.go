@@ -4,12 +4,14 @@ import (
 	"fmt"
 
 	"github.com/ryanolee/go-chaff/internal/util"
-	"github.com/thoas/go-funk"
 )
 
 type (
 	enumGenerator struct {
 		Values []interface{}
+
+		// Optional weights for each value given through "x-chaff" hints
+		Weights []float64
 	}
 )
 
@@ -30,13 +32,22 @@ func parseEnum(node schemaNode, metadata *parserMetadata) (Generator, error) {
 		return nullGenerator{}, fmt.Errorf("failed to compile schema for enum item validation: %w", err)
 	}
 
-	validEnumValues, ok := funk.Filter(*node.Enum, func(value interface{}) bool {
-		err := selfSchema.Validate(value)
-		return err == nil
-	}).([]interface{})
+	weights := getHintWeights(node.XChaff, len(*node.Enum), metadata)
+	validEnumValues := []interface{}{}
+	var validWeights []float64
+	for i, value := range *node.Enum {
+		if selfSchema.Validate(value) != nil {
+			continue
+		}
+
+		validEnumValues = append(validEnumValues, value)
+		if weights != nil {
+			validWeights = append(validWeights, weights[i])
+		}
+	}
 
-	if !ok || len(validEnumValues) == 0 {
-		return nullGenerator{}, fmt.Errorf("illogical schema, no enum values match the other schema constraints of the passed node: %v", util.MarshalJsonToString(node.Enum))
+	if len(validEnumValues) == 0 {
+		return nullGenerator{}, newDiagnosticError(DiagnosticSeverityError, DiagnosticCodeUnsatisfiable, fmt.Errorf("illogical schema, no enum values match the other schema constraints of the passed node: %v", util.MarshalJsonToString(node.Enum)))
 	}
 
 	if len(validEnumValues) == 1 {
@@ -45,13 +56,23 @@ func parseEnum(node schemaNode, metadata *parserMetadata) (Generator, error) {
 		}, nil
 	}
 
+	if validWeights != nil && validateWeights(validWeights) != nil {
+		metadata.Errors.AddErrorWithSubpath("/x-chaff/weights", fmt.Errorf("all enum values matching the other schema constraints have a weight of 0"))
+		validWeights = nil
+	}
+
 	return enumGenerator{
-		Values: validEnumValues,
+		Values:  validEnumValues,
+		Weights: validWeights,
 	}, nil
 }
 
 func (g enumGenerator) Generate(opts *GeneratorOptions) interface{} {
 	opts.overallComplexity++
+	if g.Weights != nil {
+		return g.Values[opts.Rand.WeightedIndex(g.Weights)]
+	}
+
 	return opts.Rand.Choice(g.Values)
 }
 
@@ -59,3 +80,9 @@ func (g enumGenerator) String() string {
 	numberOfItemsInEnum := len(g.Values)
 	return fmt.Sprintf("EnumGenerator[items: %d]", numberOfItemsInEnum)
 }
+
+func (g enumGenerator) describe(node *GeneratorNode) {
+	node.Kind = GeneratorKindEnum
+	node.Values = g.Values
+	node.Weights = g.Weights
+}
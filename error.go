@@ -1,6 +1,7 @@
 package chaff
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -33,6 +34,19 @@ func (ec *errorCollection) AddErrorWithSubpath(subPath string, err error) {
 	ec.Errors[document][path+subPath] = err
 }
 
+// Records the diagnostics of documents that failed meta-schema validation against the locations they were found at
+func (ec *errorCollection) addSchemaValidationErrors(validationErrors []*SchemaValidationError) {
+	for _, validationErr := range validationErrors {
+		if _, exists := ec.Errors[validationErr.Document]; !exists {
+			ec.Errors[validationErr.Document] = make(map[string]error)
+		}
+
+		for _, diagnostic := range validationErr.Diagnostics {
+			ec.Errors[validationErr.Document][diagnostic.Pointer] = newDiagnosticError(diagnostic.Severity, diagnostic.Code, errors.New(diagnostic.Message))
+		}
+	}
+}
+
 func (ec *errorCollection) AddError(err error) {
 	ec.AddErrorWithSubpath("", err)
 }
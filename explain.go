@@ -0,0 +1,429 @@
+package chaff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ryanolee/go-chaff/internal/util"
+)
+
+type (
+	// Output format for RootGenerator.Explain
+	ExplainFormat string
+
+	// Graph of the "$ref" keywords of a schema. Nodes are the root documents and every
+	// referenced schema node. An edge is added for each "$ref" pointing from the node it is nested in to its target
+	ReferenceGraph struct {
+		Nodes []ReferenceGraphNode `json:"nodes"`
+		Edges []ReferenceGraphEdge `json:"edges"`
+	}
+
+	// A schema node that references originate from or point to
+	ReferenceGraphNode struct {
+		Document string `json:"document"`
+		Path     string `json:"path"`
+
+		// Kind of generator the node compiled to
+		Kind GeneratorKind `json:"kind,omitempty"`
+
+		// Set if no generator could be found for a referenced node
+		Unresolved bool `json:"unresolved,omitempty"`
+	}
+
+	// A single "$ref" keyword
+	ReferenceGraphEdge struct {
+		From GeneratorReference `json:"from"`
+		To   GeneratorReference `json:"to"`
+
+		// Location of the "$ref" keyword itself
+		SchemaPath string `json:"schemaPath"`
+
+		// Set if the reference points into another document
+		CrossDocument bool `json:"crossDocument,omitempty"`
+
+		// Set if following the reference leads back to a node already being resolved
+		Cyclic bool `json:"cyclic,omitempty"`
+	}
+
+	// Both views of a compiled schema as rendered by RootGenerator.Explain
+	explanation struct {
+		Tree       *GeneratorNode `json:"tree"`
+		References ReferenceGraph `json:"references"`
+	}
+
+	referenceGraphBuilder struct {
+		handler *referenceHandler
+		graph   ReferenceGraph
+		visited map[GeneratorReference]bool
+		onStack map[GeneratorReference]bool
+		edges   map[GeneratorReference]bool
+	}
+)
+
+const (
+	// Indented plain text
+	ExplainFormatText ExplainFormat = "text"
+
+	// The generator tree and reference graph as JSON
+	ExplainFormatJSON ExplainFormat = "json"
+
+	// Graphviz DOT digraph
+	ExplainFormatDot ExplainFormat = "dot"
+)
+
+// Returns the formats supported by RootGenerator.Explain
+func ExplainFormats() []string {
+	return []string{string(ExplainFormatText), string(ExplainFormatJSON), string(ExplainFormatDot)}
+}
+
+// Writes the compiled generator tree and reference graph in the given format.
+// Cyclic references, null generators used as fallbacks and nodes with errors are highlighted.
+func (g RootGenerator) Explain(w io.Writer, format ExplainFormat) error {
+	tree := g.Tree()
+	graph := g.ReferenceGraph()
+
+	switch format {
+	case ExplainFormatText:
+		return writeTextExplanation(w, tree, graph)
+	case ExplainFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(explanation{Tree: tree, References: graph})
+	case ExplainFormatDot:
+		return writeDotExplanation(w, tree, graph)
+	default:
+		return fmt.Errorf("unknown explain format '%s' (supported formats: %s)", format, strings.Join(ExplainFormats(), ", "))
+	}
+}
+
+// Builds the graph of references between schema nodes including those pointing into external documents
+func (g RootGenerator) ReferenceGraph() ReferenceGraph {
+	builder := referenceGraphBuilder{
+		visited: map[GeneratorReference]bool{},
+		onStack: map[GeneratorReference]bool{},
+		edges:   map[GeneratorReference]bool{},
+		graph:   ReferenceGraph{Nodes: []ReferenceGraphNode{}, Edges: []ReferenceGraphEdge{}},
+	}
+
+	if g.Metadata != nil {
+		builder.handler = g.Metadata.ReferenceHandler
+	}
+
+	root := DescribeGenerator(g.Generator)
+	builder.visit(GeneratorReference{Document: root.Document, Path: "#"}, root)
+
+	// Definitions that are never referenced are still part of the graph
+	for _, definitions := range []map[string]Generator{g.Defs, g.Definitions} {
+		keys := util.MapKeysToStringSlice(&definitions)
+		sort.Strings(keys)
+		for _, key := range keys {
+			node := DescribeGenerator(definitions[key])
+			location := GeneratorReference{Document: node.Document, Path: node.SchemaPath}
+			if !builder.visited[location] {
+				builder.visit(location, node)
+			}
+		}
+	}
+
+	return builder.graph
+}
+
+// Adds the node to the graph and follows every reference nested within it depth first
+func (b *referenceGraphBuilder) visit(location GeneratorReference, node *GeneratorNode) {
+	b.visited[location] = true
+	b.onStack[location] = true
+	b.graph.Nodes = append(b.graph.Nodes, ReferenceGraphNode{
+		Document: location.Document,
+		Path:     location.Path,
+		Kind:     node.Kind,
+	})
+
+	node.Walk(func(child *GeneratorNode) bool {
+		if child.Reference == nil {
+			return true
+		}
+
+		// The same "$ref" can be reached from several nodes (e.g. when a definition is nested in the root)
+		refLocation := GeneratorReference{Document: child.Document, Path: child.SchemaPath}
+		if b.edges[refLocation] {
+			return true
+		}
+
+		b.edges[refLocation] = true
+		target := *child.Reference
+		b.graph.Edges = append(b.graph.Edges, ReferenceGraphEdge{
+			From:          location,
+			To:            target,
+			SchemaPath:    child.SchemaPath,
+			CrossDocument: target.Document != child.Document,
+			Cyclic:        b.onStack[target],
+		})
+
+		if !b.visited[target] {
+			b.follow(target)
+		}
+
+		return true
+	})
+
+	// No longer on the resolution stack
+	b.onStack[location] = false
+}
+
+func (b *referenceGraphBuilder) follow(target GeneratorReference) {
+	var ref reference
+	found := false
+	if b.handler != nil {
+		ref, found = b.handler.Lookup(target.Document, target.Path)
+	}
+
+	if !found || ref.Generator == nil {
+		b.visited[target] = true
+		b.graph.Nodes = append(b.graph.Nodes, ReferenceGraphNode{
+			Document:   target.Document,
+			Path:       target.Path,
+			Unresolved: true,
+		})
+		return
+	}
+
+	node := DescribeGenerator(ref.Generator)
+	node.Document, node.SchemaPath = target.Document, target.Path
+	b.visit(target, node)
+}
+
+// Returns the "$ref" keywords (by their location) that close a cycle
+func (graph ReferenceGraph) cyclicReferences() map[GeneratorReference]bool {
+	cyclic := map[GeneratorReference]bool{}
+	for _, edge := range graph.Edges {
+		if edge.Cyclic {
+			cyclic[GeneratorReference{Document: edge.From.Document, Path: edge.SchemaPath}] = true
+		}
+	}
+
+	return cyclic
+}
+
+func writeTextExplanation(w io.Writer, tree *GeneratorNode, graph ReferenceGraph) error {
+	var sb strings.Builder
+	cyclic := graph.cyclicReferences()
+
+	sb.WriteString("Generator tree:\n")
+	writeTextNode(&sb, tree, "", 1, cyclic)
+
+	sb.WriteString("\nReferences:\n")
+	if len(graph.Edges) == 0 {
+		sb.WriteString("  (none)\n")
+	}
+
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&sb, "  %s -> %s (at %s)", formatGraphLocation(edge.From), formatGraphLocation(edge.To), edge.SchemaPath)
+		if edge.CrossDocument {
+			sb.WriteString(" [cross-document]")
+		}
+
+		if edge.Cyclic {
+			sb.WriteString(" [cycle]")
+		}
+
+		sb.WriteString("\n")
+	}
+
+	for _, node := range graph.Nodes {
+		if node.Unresolved {
+			fmt.Fprintf(&sb, "  %s [unresolved]\n", formatGraphLocation(GeneratorReference{Document: node.Document, Path: node.Path}))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeTextNode(sb *strings.Builder, node *GeneratorNode, label string, depth int, cyclic map[GeneratorReference]bool) {
+	sb.WriteString(strings.Repeat("  ", depth))
+	if label != "" {
+		sb.WriteString(label)
+		sb.WriteString(": ")
+	}
+
+	sb.WriteString(describeNodeInline(node))
+	if node.Reference != nil {
+		fmt.Fprintf(sb, " -> %s", formatGraphLocation(*node.Reference))
+		if cyclic[GeneratorReference{Document: node.Document, Path: node.SchemaPath}] {
+			sb.WriteString(" [cycle]")
+		}
+	}
+
+	if node.Fallback {
+		sb.WriteString(" [fallback]")
+	}
+
+	sb.WriteString("\n")
+	for _, err := range node.Errors {
+		fmt.Fprintf(sb, "%s[error] %s\n", strings.Repeat("  ", depth+1), err)
+	}
+
+	for _, child := range node.Children {
+		writeTextNode(sb, child.Node, formatChildLabel(child), depth+1, cyclic)
+	}
+}
+
+// Short single line description of a node (e.g. `string #/properties/name {format: email, maxLength: 10}`)
+func describeNodeInline(node *GeneratorNode) string {
+	details := []string{}
+	add := func(name string, value interface{}) {
+		details = append(details, fmt.Sprintf("%s: %v", name, value))
+	}
+
+	if node.Format != "" {
+		add("format", node.Format)
+	}
+
+	if node.Pattern != "" {
+		add("pattern", node.Pattern)
+	}
+
+	if node.Provider != "" {
+		add("provider", node.Provider)
+	}
+
+	bounds := node.Bounds
+	for _, bound := range []struct {
+		name  string
+		value *float64
+	}{{"minimum", bounds.Minimum}, {"maximum", bounds.Maximum}, {"multipleOf", bounds.MultipleOf}} {
+		if bound.value != nil {
+			add(bound.name, *bound.value)
+		}
+	}
+
+	for _, bound := range []struct {
+		name  string
+		value *int
+	}{
+		{"minLength", bounds.MinLength}, {"maxLength", bounds.MaxLength},
+		{"minItems", bounds.MinItems}, {"maxItems", bounds.MaxItems}, {"minContains", bounds.MinContains},
+		{"minProperties", bounds.MinProperties}, {"maxProperties", bounds.MaxProperties},
+	} {
+		if bound.value != nil {
+			add(bound.name, *bound.value)
+		}
+	}
+
+	if bounds.UniqueItems {
+		add("uniqueItems", true)
+	}
+
+	if len(node.Values) > 0 {
+		add("values", util.MarshalJsonToString(node.Values))
+	}
+
+	if len(node.Required) > 0 {
+		add("required", strings.Join(node.Required, ","))
+	}
+
+	if len(node.Constraints) > 0 {
+		add("constraints", strings.Join(node.Constraints, "; "))
+	}
+
+	description := strings.TrimSpace(fmt.Sprintf("%s %s", node.Kind, node.SchemaPath))
+	if len(details) == 0 {
+		return description
+	}
+
+	return fmt.Sprintf("%s {%s}", description, strings.Join(details, ", "))
+}
+
+func formatChildLabel(child GeneratorChild) string {
+	if child.Key == "" {
+		return child.Relation
+	}
+
+	return fmt.Sprintf("%s[%s]", child.Relation, child.Key)
+}
+
+func formatGraphLocation(location GeneratorReference) string {
+	return fmt.Sprintf("%s%s", location.Document, location.Path)
+}
+
+func writeDotExplanation(w io.Writer, tree *GeneratorNode, graph ReferenceGraph) error {
+	var sb strings.Builder
+	sb.WriteString("digraph chaff {\n")
+	sb.WriteString("    rankdir=LR;\n")
+	sb.WriteString("    node [shape=box, fontname=\"monospace\"];\n")
+
+	// Tree nodes are numbered in walk order, referenced nodes outside of the tree are keyed by their location
+	ids := map[*GeneratorNode]string{}
+	locations := map[GeneratorReference]string{}
+	tree.Walk(func(node *GeneratorNode) bool {
+		id := fmt.Sprintf("n%d", len(ids))
+		ids[node] = id
+		if _, exists := locations[GeneratorReference{Document: node.Document, Path: node.SchemaPath}]; !exists && node.SchemaPath != "" {
+			locations[GeneratorReference{Document: node.Document, Path: node.SchemaPath}] = id
+		}
+
+		attributes := []string{fmt.Sprintf("label=%s", dotQuote(describeNodeInline(node)))}
+		switch {
+		case len(node.Errors) > 0:
+			attributes = append(attributes, "color=red", "fontcolor=red", fmt.Sprintf("tooltip=%s", dotQuote(strings.Join(node.Errors, "\n"))))
+		case node.Fallback:
+			attributes = append(attributes, "style=filled", "fillcolor=lightgrey")
+		}
+
+		fmt.Fprintf(&sb, "    %s [%s];\n", id, strings.Join(attributes, ", "))
+		return true
+	})
+
+	tree.Walk(func(node *GeneratorNode) bool {
+		for _, child := range node.Children {
+			fmt.Fprintf(&sb, "    %s -> %s [label=%s];\n", ids[node], ids[child.Node], dotQuote(formatChildLabel(child)))
+		}
+
+		return true
+	})
+
+	for i, node := range graph.Nodes {
+		location := GeneratorReference{Document: node.Document, Path: node.Path}
+		if _, exists := locations[location]; exists {
+			continue
+		}
+
+		id := fmt.Sprintf("r%d", i)
+		locations[location] = id
+		attributes := []string{fmt.Sprintf("label=%s", dotQuote(fmt.Sprintf("%s %s", node.Kind, formatGraphLocation(location)))), "shape=ellipse"}
+		if node.Unresolved {
+			attributes = append(attributes, "color=red", "fontcolor=red", "style=dashed")
+		}
+
+		fmt.Fprintf(&sb, "    %s [%s];\n", id, strings.Join(attributes, ", "))
+	}
+
+	for _, edge := range graph.Edges {
+		from := locations[GeneratorReference{Document: edge.From.Document, Path: edge.SchemaPath}]
+		if from == "" {
+			from = locations[edge.From]
+		}
+
+		attributes := []string{"style=dashed", "label=\"$ref\""}
+		if edge.CrossDocument {
+			attributes = append(attributes, "color=blue")
+		}
+
+		if edge.Cyclic {
+			attributes = append(attributes, "color=red", "penwidth=2")
+		}
+
+		fmt.Fprintf(&sb, "    %s -> %s [%s];\n", from, locations[edge.To], strings.Join(attributes, ", "))
+	}
+
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func dotQuote(value string) string {
+	return fmt.Sprintf("\"%s\"", strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value))
+}
@@ -0,0 +1,167 @@
+package chaff_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+const explainTestSchema = `{
+	"type": "object",
+	"properties": {
+		"tree": {"$ref": "#/$defs/node"},
+		"bad": {"type": "string", "pattern": "(["}
+	},
+	"$defs": {
+		"node": {
+			"type": "object",
+			"properties": {
+				"child": {"$ref": "#/$defs/node"},
+				"value": {"type": "integer", "minimum": 1}
+			}
+		}
+	}
+}`
+
+func getExplainTestGenerator(t *testing.T) chaff.RootGenerator {
+	generator, err := chaff.ParseSchemaStringWithDefaults(explainTestSchema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	return generator
+}
+
+func TestExplainReferenceGraph(t *testing.T) {
+	t.Parallel()
+	graph := getExplainTestGenerator(t).ReferenceGraph()
+
+	if len(graph.Edges) != 2 {
+		t.Fatalf("Expected 2 reference edges, got %d: %+v", len(graph.Edges), graph.Edges)
+	}
+
+	cyclic := 0
+	for _, edge := range graph.Edges {
+		if edge.To.Path != "#/$defs/node" {
+			t.Errorf("Expected edge to target #/$defs/node, got %s", edge.To.Path)
+		}
+
+		if edge.CrossDocument {
+			t.Errorf("Expected edge %s to stay within the document", edge.SchemaPath)
+		}
+
+		if edge.Cyclic {
+			cyclic++
+			if edge.SchemaPath != "#/$defs/node/properties/child" {
+				t.Errorf("Expected the self reference to be cyclic, got %s", edge.SchemaPath)
+			}
+		}
+	}
+
+	if cyclic != 1 {
+		t.Errorf("Expected exactly one cyclic edge, got %d", cyclic)
+	}
+}
+
+func TestExplainReferenceGraphCrossDocument(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaFile("test_data/document/file/cluster2_cyclic/node.json", getDocumentChaffConfig())
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	crossDocument := false
+	for _, edge := range generator.ReferenceGraph().Edges {
+		if edge.CrossDocument {
+			crossDocument = true
+			if edge.From.Document == edge.To.Document {
+				t.Errorf("Expected cross document edge to join two documents, got %s", edge.From.Document)
+			}
+		}
+	}
+
+	if !crossDocument {
+		t.Errorf("Expected at least one cross document edge")
+	}
+}
+
+func TestExplainText(t *testing.T) {
+	t.Parallel()
+	var sb strings.Builder
+	if err := getExplainTestGenerator(t).Explain(&sb, chaff.ExplainFormatText); err != nil {
+		t.Fatalf("Failed to explain generator: %s", err)
+	}
+
+	output := sb.String()
+	for _, expected := range []string{
+		"Generator tree:",
+		"References:",
+		"properties[tree]: reference #/properties/tree",
+		"[cycle]",
+		"[fallback]",
+		"[error]",
+		"invalid regex pattern",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected text explanation to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestExplainJson(t *testing.T) {
+	t.Parallel()
+	var sb strings.Builder
+	if err := getExplainTestGenerator(t).Explain(&sb, chaff.ExplainFormatJSON); err != nil {
+		t.Fatalf("Failed to explain generator: %s", err)
+	}
+
+	var explanation struct {
+		Tree       chaff.GeneratorNode  `json:"tree"`
+		References chaff.ReferenceGraph `json:"references"`
+	}
+
+	if err := json.Unmarshal([]byte(sb.String()), &explanation); err != nil {
+		t.Fatalf("Failed to unmarshal explanation: %s", err)
+	}
+
+	if explanation.Tree.Kind != chaff.GeneratorKindObject {
+		t.Errorf("Expected root kind object, got %s", explanation.Tree.Kind)
+	}
+
+	if len(explanation.Tree.Errors) == 0 {
+		t.Errorf("Expected the root to carry the pattern error")
+	}
+
+	if len(explanation.References.Edges) != 2 {
+		t.Errorf("Expected 2 reference edges, got %d", len(explanation.References.Edges))
+	}
+}
+
+func TestExplainDot(t *testing.T) {
+	t.Parallel()
+	var sb strings.Builder
+	if err := getExplainTestGenerator(t).Explain(&sb, chaff.ExplainFormatDot); err != nil {
+		t.Fatalf("Failed to explain generator: %s", err)
+	}
+
+	output := sb.String()
+	if !strings.HasPrefix(output, "digraph") || !strings.HasSuffix(strings.TrimSpace(output), "}") {
+		t.Errorf("Expected a DOT digraph, got:\n%s", output)
+	}
+
+	for _, expected := range []string{"penwidth=2", "fillcolor=lightgrey", "color=red"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected DOT explanation to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestExplainUnknownFormat(t *testing.T) {
+	t.Parallel()
+	var sb strings.Builder
+	if err := getExplainTestGenerator(t).Explain(&sb, chaff.ExplainFormat("svg")); err == nil {
+		t.Errorf("Expected an error for an unknown explain format")
+	}
+}
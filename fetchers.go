@@ -1,25 +1,79 @@
 package chaff
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/thoas/go-funk"
 )
 
+const (
+	// Scheme of documents fetched from an fs.FS
+	fsScheme = "fs"
+
+	// Defaults for fetching documents over HTTP (See HTTPFetchOptions)
+	defaultHttpFetchTimeout    = 30 * time.Second
+	defaultHttpMaxResponseSize = 10 << 20
+	maxHttpRedirects           = 10
+)
+
 type (
+	// Fetches the documents "$ref"s point to for a scheme (e.g. "registry" for "registry://schemas/order.json").
+	// Custom fetchers can be registered through DocumentFetchOptions.Fetchers. CheckHostAllowed and CheckPathAllowed
+	// can be used to apply the same allow lists as the built in HTTP and file system fetchers
+	DocumentFetcher interface {
+		// Resolves a reference (without its "#" fragment) against the ID of the document it is found in returning the
+		// ID of the document it points to. Should return an error if the document is not allowed to be fetched
+		ResolveDocumentId(relativeTo string, ref string) (string, error)
+
+		// Fetches the raw JSON source of the document with an ID returned by ResolveDocumentId
+		FetchDocument(documentId string) ([]byte, error)
+	}
+
 	httpDocumentFetcher struct {
 		// Allowed hosts to fetch from (If empty, all hosts are allowed)
 		allowedHosts []string
 
 		// Allow insecure connections (http)
 		allowInsecure bool
+
+		// Client requests are made with (Redirects are re-validated against the allowed hosts)
+		client *http.Client
+
+		// Headers sent with every request
+		headers map[string]string
+
+		// Maximum size of a response body in bytes
+		maxResponseSize int64
+
+		// Maximum number of documents to fetch (0 for no limit) and the documents counted so far
+		maxDocuments     int
+		fetchedDocuments map[string]bool
+
+		// Guards fetchedDocuments as documents may be fetched concurrently (See DocumentFetchOptions.Concurrency)
+		fetchedDocumentsLock sync.Mutex
+
+		// Cache for fetched documents (nil if caching is disabled)
+		cache *documentCache
+
+		// Only serve documents from the cache
+		offline bool
+	}
+
+	// Fetches "fs:///<path>" documents from an fs.FS (e.g. an embed.FS or fstest.MapFS)
+	fsDocumentFetcher struct {
+		fsys fs.FS
 	}
 
 	fileSystemDocumentFetcher struct {
@@ -31,7 +85,8 @@ type (
 	}
 )
 
-func NewHttpDocumentFetcher(parserConfig HTTPFetchOptions) (documentFetcherInterface, error) {
+// Returns the built in fetcher for "http" and "https" documents (nil if it is not enabled in the given options)
+func NewHttpDocumentFetcher(parserConfig HTTPFetchOptions) (DocumentFetcher, error) {
 	allowedHosts := []string{}
 
 	if !parserConfig.Enabled {
@@ -49,13 +104,136 @@ func NewHttpDocumentFetcher(parserConfig HTTPFetchOptions) (documentFetcherInter
 
 	}
 
-	return &httpDocumentFetcher{
-		allowedHosts:  allowedHosts,
-		allowInsecure: parserConfig.AllowInsecure,
-	}, nil
+	fetcher := &httpDocumentFetcher{
+		allowedHosts:     allowedHosts,
+		allowInsecure:    parserConfig.AllowInsecure,
+		headers:          parserConfig.Headers,
+		maxResponseSize:  parserConfig.MaxResponseSize,
+		maxDocuments:     parserConfig.MaxDocuments,
+		fetchedDocuments: map[string]bool{},
+	}
+
+	if fetcher.maxResponseSize <= 0 {
+		fetcher.maxResponseSize = defaultHttpMaxResponseSize
+	}
+
+	if parserConfig.Offline && parserConfig.CacheDir == "" {
+		return nil, errors.New("fetching documents offline requires a cache directory to serve them from")
+	}
+
+	if parserConfig.CacheDir != "" {
+		cache, err := newDocumentCache(parserConfig.CacheDir, parserConfig.CacheTTL)
+		if err != nil {
+			return nil, err
+		}
+
+		fetcher.cache = cache
+		fetcher.offline = parserConfig.Offline
+	}
+
+	client, err := fetcher.newClient(parserConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher.client = client
+	return fetcher, nil
 }
 
-func (f *httpDocumentFetcher) resolveDocumentId(relativeTo string, ref string) (string, error) {
+// Returns a copy of the configured client (or a new one) with a timeout and redirects limited to allowed URLs
+func (f *httpDocumentFetcher) newClient(parserConfig HTTPFetchOptions) (*http.Client, error) {
+	client := &http.Client{}
+	if parserConfig.Client != nil {
+		clientCopy := *parserConfig.Client
+		client = &clientCopy
+	}
+
+	if parserConfig.Timeout > 0 {
+		client.Timeout = parserConfig.Timeout
+	} else if client.Timeout == 0 {
+		client.Timeout = defaultHttpFetchTimeout
+	}
+
+	if parserConfig.BlockPrivateNetworks {
+		transport, err := newPrivateNetworkBlockingTransport(client.Transport)
+		if err != nil {
+			return nil, err
+		}
+
+		client.Transport = transport
+	}
+
+	checkRedirect := client.CheckRedirect
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxHttpRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxHttpRedirects)
+		}
+
+		if err := f.checkUrlAllowed(req.URL); err != nil {
+			return fmt.Errorf("redirect to '%s' is not allowed: %w", req.URL, err)
+		}
+
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+
+		return nil
+	}
+
+	return client, nil
+}
+
+// Returns a transport that refuses to connect to loopback, private, link-local or unspecified IPs. These are checked
+// once DNS has been resolved so hosts resolving to them are blocked too. Proxies are not used as they would hide the
+// address being connected to
+func newPrivateNetworkBlockingTransport(base http.RoundTripper) (*http.Transport, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return nil, errors.New("blocking private networks requires the HTTP client to use an *http.Transport")
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || isPrivateNetworkIp(ip) {
+				return fmt.Errorf("connecting to '%s' is not allowed as it is a loopback, private or link-local address", host)
+			}
+
+			return nil
+		},
+	}
+
+	transport = transport.Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport, nil
+}
+
+func isPrivateNetworkIp(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
+
+// Returns an error if the given URL may not be fetched from
+func (f *httpDocumentFetcher) checkUrlAllowed(documentUrl *url.URL) error {
+	if documentUrl.Scheme != "https" && (!f.allowInsecure || documentUrl.Scheme != "http") {
+		return fmt.Errorf("insecure URL scheme '%s' not allowed for URL '%s'", documentUrl.Scheme, documentUrl)
+	}
+
+	return CheckHostAllowed(documentUrl.Host, f.allowedHosts)
+}
+
+func (f *httpDocumentFetcher) ResolveDocumentId(relativeTo string, ref string) (string, error) {
 	parsedUrl, err := url.Parse(ref)
 
 	if err != nil {
@@ -78,41 +256,133 @@ func (f *httpDocumentFetcher) resolveDocumentId(relativeTo string, ref string) (
 		return "", fmt.Errorf("insecure URL scheme '%s' not allowed for URL '%s'", parsedUrl.Scheme, ref)
 	}
 
-	if len(f.allowedHosts) > 0 {
-		if !funk.Contains(f.allowedHosts, resolvedUrl.Host) {
-			return "", fmt.Errorf("host '%s' not allowed to be fetched from allowed hosts %s", resolvedUrl.Host, strings.Join(f.allowedHosts, ", "))
-		}
+	if err := CheckHostAllowed(resolvedUrl.Host, f.allowedHosts); err != nil {
+		return "", err
 	}
 
 	return resolvedUrl.String(), nil
 }
 
-func (f *httpDocumentFetcher) fetchDocument(resolvedPath string) (*schemaNode, error) {
-	resp, err := http.Get(resolvedPath)
+// Returns an error if the given host is not one of the allowed hosts (All hosts are allowed if none are given)
+func CheckHostAllowed(host string, allowedHosts []string) error {
+	if len(allowedHosts) > 0 && !funk.ContainsString(allowedHosts, host) {
+		return fmt.Errorf("host '%s' not allowed to be fetched from allowed hosts %s", host, strings.Join(allowedHosts, ", "))
+	}
+
+	return nil
+}
+
+func (f *httpDocumentFetcher) FetchDocument(resolvedPath string) ([]byte, error) {
+	var entry *documentCacheEntry
+	var cached []byte
+	if f.cache != nil {
+		entry, cached = f.cache.load(resolvedPath)
+		if entry != nil && (f.offline || f.cache.isFresh(entry)) {
+			return cached, nil
+		}
+	}
+
+	if f.offline {
+		return nil, fmt.Errorf("failed to fetch URL '%s': it is not cached and documents are only served from the cache when offline", resolvedPath)
+	}
+
+	if err := f.countFetchedDocument(resolvedPath); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, resolvedPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for URL '%s': %w", resolvedPath, err)
+	}
+
+	for name, value := range f.headers {
+		req.Header.Set(name, value)
+	}
+
+	// Stale cached documents are revalidated rather than downloaded again
+	if entry != nil && entry.ETag != "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	if entry != nil && entry.LastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.LastModified)
+	}
+
+	resp, err := f.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch URL '%s': %w", resolvedPath, err)
 	}
 	defer resp.Body.Close()
 
+	if entry != nil && resp.StatusCode == http.StatusNotModified {
+		// Failing to cache a document does not fail the fetch as it is only fetched again next time
+		entry.FetchedAt = time.Now()
+		_ = f.cache.store(*entry, cached)
+		return cached, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("failed to fetch URL '%s': received status code %d", resolvedPath, resp.StatusCode)
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	// Read one byte past the limit to tell a body of exactly the maximum size apart from a larger one
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.maxResponseSize+1))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body from URL '%s': %w", resolvedPath, err)
 	}
 
-	schemaNode := &schemaNode{}
-	if err = json.Unmarshal(data, schemaNode); err != nil {
-		return nil, fmt.Errorf("failed to parse Schema json from URL '%s': %w", resolvedPath, err)
+	if int64(len(data)) > f.maxResponseSize {
+		return nil, fmt.Errorf("failed to fetch URL '%s': response body exceeds the maximum size of %d bytes", resolvedPath, f.maxResponseSize)
+	}
+
+	if f.cache != nil {
+		_ = f.cache.store(documentCacheEntry{
+			Url:          resolvedPath,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			FetchedAt:    time.Now(),
+		}, data)
 	}
 
-	return schemaNode, nil
+	return data, nil
+}
+
+// Counts a document queued to be fetched in the background (See documentReserver). Documents that will be
+// served from the cache are left uncounted as they are when fetched
+func (f *httpDocumentFetcher) reserveDocument(resolvedPath string) error {
+	if f.cache != nil {
+		if entry, _ := f.cache.load(resolvedPath); entry != nil && (f.offline || f.cache.isFresh(entry)) {
+			return nil
+		}
+	}
+
+	if f.offline {
+		return nil
+	}
 
+	return f.countFetchedDocument(resolvedPath)
 }
 
-func NewFileSystemDocumentFetcher(config FileSystemFetchOptions) (documentFetcherInterface, error) {
+// Counts a document about to be fetched returning an error if the maximum number of documents has been fetched.
+// Documents already counted when they were reserved are not counted again
+func (f *httpDocumentFetcher) countFetchedDocument(resolvedPath string) error {
+	f.fetchedDocumentsLock.Lock()
+	defer f.fetchedDocumentsLock.Unlock()
+
+	if f.fetchedDocuments[resolvedPath] {
+		return nil
+	}
+
+	if f.maxDocuments > 0 && len(f.fetchedDocuments) >= f.maxDocuments {
+		return fmt.Errorf("failed to fetch URL '%s': the maximum of %d documents have already been fetched", resolvedPath, f.maxDocuments)
+	}
+
+	f.fetchedDocuments[resolvedPath] = true
+	return nil
+}
+
+// Returns the built in fetcher for "file" documents (nil if it is not enabled in the given options)
+func NewFileSystemDocumentFetcher(config FileSystemFetchOptions) (DocumentFetcher, error) {
 	if !config.Enabled {
 		return nil, nil
 	}
@@ -132,7 +402,7 @@ func NewFileSystemDocumentFetcher(config FileSystemFetchOptions) (documentFetche
 	}, nil
 }
 
-func (f *fileSystemDocumentFetcher) resolveDocumentId(relativeTo string, ref string) (string, error) {
+func (f *fileSystemDocumentFetcher) ResolveDocumentId(relativeTo string, ref string) (string, error) {
 	// Read based on file:// scheme
 	parsedUrl, err := url.Parse(ref)
 	if err != nil {
@@ -156,47 +426,97 @@ func (f *fileSystemDocumentFetcher) resolveDocumentId(relativeTo string, ref str
 		return "", fmt.Errorf("failed to resolve file path for URL '%s' relative to '%s': %w", ref, relativeTo, err)
 	}
 
-	if !f.allowOutsideCwd {
-		outsideCwd, err := f.isOutsideOfCwd(resolvedPath)
+	if err := CheckPathAllowed(resolvedPath, f.allowedPaths, f.allowOutsideCwd); err != nil {
+		return "", err
+	}
+
+	return "file://" + resolvedPath, nil
+}
+
+func (f *fileSystemDocumentFetcher) FetchDocument(resolvedPath string) ([]byte, error) {
+	resolvedPath = strings.TrimPrefix(resolvedPath, "file://")
+	fileData, err := os.ReadFile(resolvedPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file at path '%s': %w", resolvedPath, err)
+	}
+
+	return fileData, nil
+}
+
+// Returns an error if the given absolute path is outside of the allowed paths or outside of the
+// current working directory when that is not allowed (See FileSystemFetchOptions)
+func CheckPathAllowed(path string, allowedPaths []string, allowOutsideCwd bool) error {
+	if !allowOutsideCwd {
+		outsideCwd, err := isOutsideOfCwd(path)
 		if err != nil {
-			return "", fmt.Errorf("failed to check if path '%s' is outside of current working directory: %w", resolvedPath, err)
+			return fmt.Errorf("failed to check if path '%s' is outside of current working directory: %w", path, err)
 		}
 
 		cwd, _ := os.Getwd()
 
 		if outsideCwd {
-			return "", fmt.Errorf("access to path '%s' outside of current working directory '%s' is not allowed", resolvedPath, cwd)
+			return fmt.Errorf("access to path '%s' outside of current working directory '%s' is not allowed", path, cwd)
 		}
 	}
 
-	outsideAllowedPaths, err := isOutsideOfAllowedPaths(resolvedPath, f.allowedPaths)
+	outsideAllowedPaths, err := isOutsideOfAllowedPaths(path, allowedPaths)
 	if err != nil {
-		return "", fmt.Errorf("failed to check if path '%s' is outside of allowed paths: %w", resolvedPath, err)
+		return fmt.Errorf("failed to check if path '%s' is outside of allowed paths: %w", path, err)
 	}
 
 	if outsideAllowedPaths {
-		return "", fmt.Errorf("access to path '%s' is not allowed. Only paths files in the following paths are allowed: %s", resolvedPath, strings.Join(f.allowedPaths, ", "))
+		return fmt.Errorf("access to path '%s' is not allowed. Only paths files in the following paths are allowed: %s", path, strings.Join(allowedPaths, ", "))
 	}
 
-	return "file://" + resolvedPath, nil
+	return nil
 }
 
-func (f *fileSystemDocumentFetcher) fetchDocument(resolvedPath string) (*schemaNode, error) {
-	resolvedPath = strings.TrimPrefix(resolvedPath, "file://")
-	fileData, err := os.ReadFile(resolvedPath)
+// Returns a fetcher for documents in the given fs.FS. Documents are identified as "fs:///<path>"
+// with relative references resolved against the document they are found in. References can not
+// point outside of the root of the fs.FS (See ParseSchemaFS)
+func NewFSDocumentFetcher(fsys fs.FS) DocumentFetcher {
+	return &fsDocumentFetcher{fsys: fsys}
+}
+
+func (f *fsDocumentFetcher) ResolveDocumentId(relativeTo string, ref string) (string, error) {
+	parsedUrl, err := url.Parse(ref)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file at path '%s': %w", resolvedPath, err)
+		return "", fmt.Errorf("invalid fs URL '%s': %w", ref, err)
+	}
+
+	if parsedUrl.Scheme != fsScheme && parsedUrl.Scheme != "" {
+		return "", fmt.Errorf("invalid fs URL scheme '%s' for URL '%s'", parsedUrl.Scheme, ref)
+	}
+
+	baseUrl, err := url.Parse(relativeTo)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL '%s': %w", relativeTo, err)
+	}
+
+	if baseUrl.Scheme == fsScheme {
+		parsedUrl = baseUrl.ResolveReference(parsedUrl)
+	}
+
+	// URL resolution never goes above the root so references can not escape the file system
+	filePath := strings.TrimPrefix(parsedUrl.Path, "/")
+	if !fs.ValidPath(filePath) {
+		return "", fmt.Errorf("invalid path '%s' for URL '%s'", filePath, ref)
 	}
 
-	schemaNode := &schemaNode{}
-	if err = json.Unmarshal(fileData, schemaNode); err != nil {
-		return nil, fmt.Errorf("failed to parse Schema json from file at path '%s': %w", resolvedPath, err)
+	return fsScheme + ":///" + filePath, nil
+}
+
+func (f *fsDocumentFetcher) FetchDocument(documentId string) ([]byte, error) {
+	filePath := strings.TrimPrefix(documentId, fsScheme+":///")
+	data, err := fs.ReadFile(f.fsys, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file at path '%s': %w", filePath, err)
 	}
 
-	return schemaNode, nil
+	return data, nil
 }
 
-func (f *fileSystemDocumentFetcher) isOutsideOfCwd(path string) (bool, error) {
+func isOutsideOfCwd(path string) (bool, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return false, fmt.Errorf("failed to get current working directory: %w", err)
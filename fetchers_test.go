@@ -0,0 +1,514 @@
+package chaff_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+// Fetches documents from memory using the same host allow list as the HTTP fetcher
+type registryDocumentFetcher struct {
+	documents    map[string]string
+	allowedHosts []string
+}
+
+func (f registryDocumentFetcher) ResolveDocumentId(relativeTo string, ref string) (string, error) {
+	base, err := url.Parse(relativeTo)
+	if err != nil {
+		return "", err
+	}
+
+	resolved, err := base.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+
+	if err := chaff.CheckHostAllowed(resolved.Host, f.allowedHosts); err != nil {
+		return "", err
+	}
+
+	return resolved.String(), nil
+}
+
+func (f registryDocumentFetcher) FetchDocument(documentId string) ([]byte, error) {
+	document, ok := f.documents[documentId]
+	if !ok {
+		return nil, fmt.Errorf("document '%s' not found in the registry", documentId)
+	}
+
+	return []byte(document), nil
+}
+
+func getRegistryChaffConfig(allowedHosts ...string) *chaff.ParserOptions {
+	return &chaff.ParserOptions{
+		RelativeTo: "registry://team/root.json",
+		DocumentFetchOptions: chaff.DocumentFetchOptions{
+			Fetchers: map[string]chaff.DocumentFetcher{
+				"registry": registryDocumentFetcher{
+					allowedHosts: allowedHosts,
+					documents: map[string]string{
+						"registry://team/customer.json":       `{"type": "object", "properties": {"address": {"$ref": "shared/address.json#/$defs/address"}}, "required": ["address"]}`,
+						"registry://team/shared/address.json": `{"$defs": {"address": {"const": "1 Main Street"}}}`,
+						"registry://other/customer.json":      `{"const": "other"}`,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestCustomDocumentFetcher(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaString(`{
+		"type": "object",
+		"properties": {"customer": {"$ref": "customer.json"}},
+		"required": ["customer"]
+	}`, getRegistryChaffConfig("team"))
+
+	if err != nil || generator.Metadata.Errors.HasErrors() {
+		t.Fatalf("Failed to parse schema: %v %v", err, generator.Metadata.Errors.CollectErrors())
+	}
+
+	result := generator.GenerateWithDefaults().(map[string]interface{})
+	customer := result["customer"].(map[string]interface{})
+	if customer["address"] != "1 Main Street" {
+		t.Errorf("Expected the address to be fetched through the registry, got %v", result)
+	}
+}
+
+func TestCustomDocumentFetcherAllowList(t *testing.T) {
+	t.Parallel()
+	generator, _ := chaff.ParseSchemaString(`{"$ref": "registry://other/customer.json"}`, getRegistryChaffConfig("team"))
+
+	found := false
+	for _, err := range generator.Metadata.Errors.CollectErrors() {
+		found = found || strings.Contains(err.Error(), "host 'other' not allowed")
+	}
+
+	if !found {
+		t.Errorf("Expected the host allow list to be applied, got %v", generator.Metadata.Errors.CollectErrors())
+	}
+}
+
+func TestCustomDocumentFetcherDisablesScheme(t *testing.T) {
+	t.Parallel()
+	opts := getDocumentChaffConfig()
+	opts.DocumentFetchOptions.Fetchers = map[string]chaff.DocumentFetcher{"file": nil}
+	generator, _ := chaff.ParseSchemaString(`{"$ref": "test_data/document/file/cluster1_simple_refs/address.json"}`, opts)
+
+	if !generator.Metadata.Errors.HasErrors() {
+		t.Errorf("Expected the file scheme to be disabled")
+	}
+}
+
+// Returns the errors reported while parsing the schema containing the given text
+func findParseErrors(generator chaff.RootGenerator, text string) []error {
+	found := []error{}
+	for _, err := range generator.Metadata.Errors.CollectErrors() {
+		if strings.Contains(err.Error(), text) {
+			found = append(found, err)
+		}
+	}
+
+	return found
+}
+
+func getHttpTestChaffConfig(servers ...*httptest.Server) *chaff.ParserOptions {
+	allowedHosts := []string{}
+	for _, server := range servers {
+		allowedHosts = append(allowedHosts, strings.TrimPrefix(server.URL, "http://"))
+	}
+
+	return &chaff.ParserOptions{
+		DocumentFetchOptions: chaff.DocumentFetchOptions{
+			HTTPFetchOptions: chaff.HTTPFetchOptions{
+				Enabled:       true,
+				AllowInsecure: true,
+				AllowedHosts:  allowedHosts,
+			},
+		},
+	}
+}
+
+func TestHttpFetcherHeaders(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Write([]byte(`{"const": "authorized"}`))
+	}))
+	defer server.Close()
+
+	opts := getHttpTestChaffConfig(server)
+	opts.DocumentFetchOptions.HTTPFetchOptions.Headers = map[string]string{"Authorization": "Bearer token"}
+	generator, err := chaff.ParseSchemaString(fmt.Sprintf(`{"$ref": "%s/schema.json"}`, server.URL), opts)
+	if err != nil || generator.Metadata.Errors.HasErrors() {
+		t.Fatalf("Failed to parse schema: %v %v", err, generator.Metadata.Errors.CollectErrors())
+	}
+
+	if result := generator.GenerateWithDefaults(); result != "authorized" {
+		t.Errorf("Expected the headers to be sent, got %v", result)
+	}
+}
+
+func TestHttpFetcherLimits(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow.json":
+			time.Sleep(200 * time.Millisecond)
+		case "/large.json":
+			w.Write([]byte(fmt.Sprintf(`{"description": "%s"}`, strings.Repeat("a", 100))))
+			return
+		}
+
+		w.Write([]byte(`{"type": "string"}`))
+	}))
+	defer server.Close()
+
+	opts := getHttpTestChaffConfig(server)
+	opts.DocumentFetchOptions.HTTPFetchOptions.Timeout = 50 * time.Millisecond
+	opts.DocumentFetchOptions.HTTPFetchOptions.MaxResponseSize = 64
+	generator, _ := chaff.ParseSchemaString(fmt.Sprintf(`{
+		"type": "object",
+		"properties": {
+			"slow": {"$ref": "%[1]s/slow.json"},
+			"large": {"$ref": "%[1]s/large.json"},
+			"small": {"$ref": "%[1]s/small.json"}
+		}
+	}`, server.URL), opts)
+
+	for _, expected := range []string{"Client.Timeout exceeded", "exceeds the maximum size of 64 bytes"} {
+		if len(findParseErrors(generator, expected)) != 1 {
+			t.Errorf("Expected an error containing '%s', got %v", expected, generator.Metadata.Errors.CollectErrors())
+		}
+	}
+
+	opts = getHttpTestChaffConfig(server)
+	opts.DocumentFetchOptions.HTTPFetchOptions.MaxDocuments = 2
+	generator, _ = chaff.ParseSchemaString(fmt.Sprintf(`{
+		"type": "object",
+		"properties": {
+			"first": {"$ref": "%[1]s/first.json"},
+			"second": {"$ref": "%[1]s/second.json"},
+			"third": {"$ref": "%[1]s/third.json"}
+		}
+	}`, server.URL), opts)
+
+	if len(findParseErrors(generator, "the maximum of 2 documents have already been fetched")) != 1 {
+		t.Errorf("Expected only 2 documents to be fetched, got %v", generator.Metadata.Errors.CollectErrors())
+	}
+}
+
+func TestHttpFetcherRedirects(t *testing.T) {
+	t.Parallel()
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"const": "redirected"}`))
+	}))
+	defer target.Close()
+
+	server := httptest.NewServer(http.RedirectHandler(target.URL+"/schema.json", http.StatusFound))
+	defer server.Close()
+
+	schema := fmt.Sprintf(`{"$ref": "%s/schema.json"}`, server.URL)
+	generator, _ := chaff.ParseSchemaString(schema, getHttpTestChaffConfig(server))
+	if len(findParseErrors(generator, "redirect to '"+target.URL+"/schema.json' is not allowed")) == 0 {
+		t.Errorf("Expected redirects to hosts that are not allowed to fail, got %v", generator.Metadata.Errors.CollectErrors())
+	}
+
+	generator, err := chaff.ParseSchemaString(schema, getHttpTestChaffConfig(server, target))
+	if err != nil || generator.Metadata.Errors.HasErrors() {
+		t.Fatalf("Failed to parse schema: %v %v", err, generator.Metadata.Errors.CollectErrors())
+	}
+
+	if result := generator.GenerateWithDefaults(); result != "redirected" {
+		t.Errorf("Expected redirects to allowed hosts to be followed, got %v", result)
+	}
+}
+
+func TestHttpFetcherBlockPrivateNetworks(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"type": "string"}`))
+	}))
+	defer server.Close()
+
+	opts := getHttpTestChaffConfig(server)
+	opts.DocumentFetchOptions.HTTPFetchOptions.BlockPrivateNetworks = true
+	generator, _ := chaff.ParseSchemaString(fmt.Sprintf(`{"$ref": "%s/schema.json"}`, server.URL), opts)
+
+	if len(findParseErrors(generator, "connecting to '127.0.0.1' is not allowed")) == 0 || requests != 0 {
+		t.Errorf("Expected loopback addresses to be blocked, got %v", generator.Metadata.Errors.CollectErrors())
+	}
+
+	opts.DocumentFetchOptions.HTTPFetchOptions.Client = &http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}
+	if _, err := chaff.ParseSchemaString(`{"type": "string"}`, opts); err == nil {
+		t.Errorf("Expected blocking private networks to require an *http.Transport")
+	}
+}
+
+func TestHttpFetcherCustomClient(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"const": "custom"}`))
+	}))
+	defer server.Close()
+
+	requests := 0
+	opts := getHttpTestChaffConfig(server)
+	opts.DocumentFetchOptions.HTTPFetchOptions.Client = &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			requests++
+			return http.DefaultTransport.RoundTrip(r)
+		}),
+	}
+
+	generator, err := chaff.ParseSchemaString(fmt.Sprintf(`{"$ref": "%s/schema.json"}`, server.URL), opts)
+	if err != nil || generator.Metadata.Errors.HasErrors() || requests != 1 {
+		t.Errorf("Expected the custom client to be used, got %d requests %v %v", requests, err, generator.Metadata.Errors.CollectErrors())
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestHttpFetcherCache(t *testing.T) {
+	t.Parallel()
+	requests, revalidations := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"const": "cached"}`))
+	}))
+	defer server.Close()
+
+	opts := getHttpTestChaffConfig(server)
+	opts.DocumentFetchOptions.HTTPFetchOptions.CacheDir = t.TempDir()
+	schema := fmt.Sprintf(`{"$ref": "%s/schema.json"}`, server.URL)
+	for i, expected := range []struct {
+		ttl           time.Duration
+		requests      int
+		revalidations int
+	}{
+		{ttl: 0, requests: 1, revalidations: 0},
+		{ttl: 0, requests: 2, revalidations: 1},
+		{ttl: time.Hour, requests: 2, revalidations: 1},
+	} {
+		opts.DocumentFetchOptions.HTTPFetchOptions.CacheTTL = expected.ttl
+		generator, err := chaff.ParseSchemaString(schema, opts)
+		if err != nil || generator.Metadata.Errors.HasErrors() {
+			t.Fatalf("Failed to parse schema: %v %v", err, generator.Metadata.Errors.CollectErrors())
+		}
+
+		if result := generator.GenerateWithDefaults(); result != "cached" {
+			t.Errorf("Run %d: expected the cached document to be used, got %v", i, result)
+		}
+
+		if requests != expected.requests || revalidations != expected.revalidations {
+			t.Errorf("Run %d: expected %d requests and %d revalidations, got %d and %d", i, expected.requests, expected.revalidations, requests, revalidations)
+		}
+	}
+}
+
+func TestHttpFetcherCacheWriteFailure(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"const": "uncached"}`))
+	}))
+	defer server.Close()
+
+	// A directory in place of the cached document makes writing it fail
+	cacheDir := t.TempDir()
+	documentUrl := server.URL + "/schema.json"
+	sum := sha256.Sum256([]byte(documentUrl))
+	if err := os.Mkdir(filepath.Join(cacheDir, hex.EncodeToString(sum[:])+".json"), 0755); err != nil {
+		t.Fatalf("Failed to create cache directory: %s", err)
+	}
+
+	opts := getHttpTestChaffConfig(server)
+	opts.DocumentFetchOptions.HTTPFetchOptions.CacheDir = cacheDir
+	generator, err := chaff.ParseSchemaString(fmt.Sprintf(`{"$ref": "%s"}`, documentUrl), opts)
+	if err != nil || generator.Metadata.Errors.HasErrors() {
+		t.Fatalf("Expected failing to cache the document not to fail the fetch: %v %v", err, generator.Metadata.Errors.CollectErrors())
+	}
+
+	if result := generator.GenerateWithDefaults(); result != "uncached" {
+		t.Errorf("Expected the fetched document to be used, got %v", result)
+	}
+}
+
+func TestHttpFetcherOffline(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"const": "offline"}`))
+	}))
+
+	opts := getHttpTestChaffConfig(server)
+	opts.DocumentFetchOptions.HTTPFetchOptions.CacheDir = t.TempDir()
+	schema := fmt.Sprintf(`{"$ref": "%s/schema.json"}`, server.URL)
+	if _, err := chaff.ParseSchemaString(schema, opts); err != nil {
+		t.Fatalf("Failed to warm up the cache: %s", err)
+	}
+
+	server.Close()
+	opts.DocumentFetchOptions.HTTPFetchOptions.Offline = true
+	generator, err := chaff.ParseSchemaString(schema, opts)
+	if err != nil || generator.Metadata.Errors.HasErrors() {
+		t.Fatalf("Failed to parse schema offline: %v %v", err, generator.Metadata.Errors.CollectErrors())
+	}
+
+	if result := generator.GenerateWithDefaults(); result != "offline" {
+		t.Errorf("Expected the document to be served from the cache, got %v", result)
+	}
+
+	generator, _ = chaff.ParseSchemaString(fmt.Sprintf(`{"$ref": "%s/missing.json"}`, server.URL), opts)
+	if len(findParseErrors(generator, "it is not cached")) == 0 {
+		t.Errorf("Expected uncached documents to fail offline, got %v", generator.Metadata.Errors.CollectErrors())
+	}
+
+	opts.DocumentFetchOptions.HTTPFetchOptions.CacheDir = ""
+	if _, err := chaff.ParseSchemaString(schema, opts); err == nil {
+		t.Errorf("Expected offline mode to require a cache directory")
+	}
+}
+
+func TestDocumentFetchConcurrency(t *testing.T) {
+	t.Parallel()
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		var index int
+		switch {
+		case r.URL.Path == "/missing.json":
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasPrefix(r.URL.Path, "/nested/"):
+			fmt.Sscanf(r.URL.Path, "/nested/%d.json", &index)
+			w.Write([]byte(fmt.Sprintf(`{"const": %d}`, index)))
+		default:
+			fmt.Sscanf(r.URL.Path, "/%d.json", &index)
+			w.Write([]byte(fmt.Sprintf(`{"type": "object", "properties": {"value": {"$ref": "nested/%d.json"}}, "required": ["value"]}`, index)))
+		}
+	}))
+	defer server.Close()
+
+	properties := []string{fmt.Sprintf(`"missing": {"$ref": "%s/missing.json"}`, server.URL)}
+	required := []string{}
+	for i := 0; i < 8; i++ {
+		properties = append(properties, fmt.Sprintf(`"%[1]d": {"$ref": "%[2]s/%[1]d.json"}`, i, server.URL))
+		required = append(required, fmt.Sprintf(`"%d"`, i))
+	}
+
+	schema := fmt.Sprintf(`{"type": "object", "properties": {%s}, "required": [%s]}`, strings.Join(properties, ", "), strings.Join(required, ", "))
+	serial, _ := chaff.ParseSchemaString(schema, getHttpTestChaffConfig(server))
+	if seen := atomic.LoadInt32(&maxInFlight); seen != 1 {
+		t.Errorf("Expected documents to be fetched one at a time by default, got %d at once", seen)
+	}
+
+	atomic.StoreInt32(&maxInFlight, 0)
+	opts := getHttpTestChaffConfig(server)
+	opts.DocumentFetchOptions.Concurrency = 4
+	concurrent, _ := chaff.ParseSchemaString(schema, opts)
+	if seen := atomic.LoadInt32(&maxInFlight); seen < 2 || seen > 4 {
+		t.Errorf("Expected between 2 and 4 documents to be fetched at once, got %d", seen)
+	}
+
+	// Errors are reported against the same documents and paths whether documents are fetched concurrently or not
+	serialErrors, concurrentErrors := serial.Metadata.Errors.CollectErrors(), concurrent.Metadata.Errors.CollectErrors()
+	if len(serialErrors) != 1 || len(concurrentErrors) != len(serialErrors) {
+		t.Fatalf("Expected the missing document to be reported once, got %v and %v", serialErrors, concurrentErrors)
+	}
+
+	for key, err := range serialErrors {
+		if concurrentErr, ok := concurrentErrors[key]; !ok || concurrentErr.Error() != err.Error() {
+			t.Errorf("Expected error '%s: %s' when fetching concurrently, got %v", key, err, concurrentErrors)
+		}
+	}
+
+	result, ok := concurrent.GenerateWithDefaults().(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected an object to be generated")
+	}
+
+	for i := 0; i < 8; i++ {
+		value, ok := result[fmt.Sprint(i)].(map[string]interface{})
+		if !ok || value["value"] != float64(i) {
+			t.Errorf("Expected property %d to resolve to its nested document, got %v", i, result[fmt.Sprint(i)])
+		}
+	}
+}
+
+func TestDocumentFetchConcurrencyMaxDocuments(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Later documents respond first so fetches finish in a different order than they were queued
+		var index int
+		fmt.Sscanf(r.URL.Path, "/%d.json", &index)
+		time.Sleep(time.Duration(8-index) * 5 * time.Millisecond)
+		w.Write([]byte(`{"type": "string"}`))
+	}))
+	defer server.Close()
+
+	// Documents are referenced from an array so they are always queued in the same order
+	refs := []string{}
+	for i := 0; i < 8; i++ {
+		refs = append(refs, fmt.Sprintf(`{"$ref": "%s/%d.json"}`, server.URL, i))
+	}
+
+	schema := fmt.Sprintf(`{"type": "array", "prefixItems": [%s]}`, strings.Join(refs, ", "))
+	opts := getHttpTestChaffConfig(server)
+	opts.DocumentFetchOptions.HTTPFetchOptions.MaxDocuments = 3
+	serial, _ := chaff.ParseSchemaString(schema, opts)
+	serialErrors := serial.Metadata.Errors.CollectErrors()
+	if len(serialErrors) != 5 {
+		t.Fatalf("Expected 5 documents to exceed the maximum, got %v", serialErrors)
+	}
+
+	opts.DocumentFetchOptions.Concurrency = 4
+	for i := 0; i < 5; i++ {
+		concurrent, _ := chaff.ParseSchemaString(schema, opts)
+		concurrentErrors := concurrent.Metadata.Errors.CollectErrors()
+		if len(concurrentErrors) != len(serialErrors) {
+			t.Fatalf("Expected the same documents to exceed the maximum when fetching concurrently, got %v and %v", serialErrors, concurrentErrors)
+		}
+
+		for key := range serialErrors {
+			if _, ok := concurrentErrors[key]; !ok {
+				t.Errorf("Expected '%s' to exceed the maximum when fetching concurrently, got %v", key, concurrentErrors)
+			}
+		}
+	}
+}
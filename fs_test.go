@@ -0,0 +1,94 @@
+package chaff_test
+
+import (
+	"embed"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+//go:embed test_data/document/file/cluster4_deep_nested
+var deepNestedSchemas embed.FS
+
+func TestParseSchemaFSEmbedded(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaFS(deepNestedSchemas, "test_data/document/file/cluster4_deep_nested/main.json", &chaff.ParserOptions{})
+	if err != nil || generator.Metadata.Errors.HasErrors() {
+		t.Fatalf("Failed to parse schema: %v %v", err, generator.Metadata.Errors.CollectErrors())
+	}
+
+	result, ok := generator.GenerateWithDefaults().(map[string]interface{})
+	if !ok || result["config"] == nil || result["users"] == nil {
+		t.Errorf("Expected the embedded documents to be resolved, got %v", result)
+	}
+}
+
+func TestParseSchemaFSRelativeReferences(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"schemas/order.json":           {Data: []byte(`{"type": "object", "properties": {"customer": {"$ref": "people/customer.json"}}, "required": ["customer"]}`)},
+		"schemas/people/customer.json": {Data: []byte(`{"$ref": "../shared.json#/$defs/name"}`)},
+		"schemas/shared.json":          {Data: []byte(`{"$defs": {"name": {"const": "Ada"}}}`)},
+	}
+
+	generator, err := chaff.ParseSchemaFS(fsys, "schemas/order.json", &chaff.ParserOptions{})
+	if err != nil || generator.Metadata.Errors.HasErrors() {
+		t.Fatalf("Failed to parse schema: %v %v", err, generator.Metadata.Errors.CollectErrors())
+	}
+
+	result := generator.GenerateWithDefaults().(map[string]interface{})
+	if result["customer"] != "Ada" {
+		t.Errorf("Expected relative references to resolve within the file system, got %v", result)
+	}
+}
+
+func TestParseSchemaFSMissingDocument(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"order.json": {Data: []byte(`{"$ref": "../../etc/customer.json"}`)},
+	}
+
+	generator, err := chaff.ParseSchemaFS(fsys, "order.json", &chaff.ParserOptions{})
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	found := false
+	for _, err := range generator.Metadata.Errors.CollectErrors() {
+		found = found || strings.Contains(err.Error(), "failed to read file at path 'etc/customer.json'")
+	}
+
+	if !found {
+		t.Errorf("Expected references to stay within the file system, got %v", generator.Metadata.Errors.CollectErrors())
+	}
+
+	if _, err := chaff.ParseSchemaFS(fsys, "missing.json", &chaff.ParserOptions{}); err == nil {
+		t.Errorf("Expected a missing root schema to fail")
+	}
+}
+
+func TestParseSchemaFSReusedOptions(t *testing.T) {
+	t.Parallel()
+	opts := &chaff.ParserOptions{}
+	for _, name := range []string{"first", "second"} {
+		fsys := fstest.MapFS{
+			"main.json": {Data: []byte(`{"$ref": "name.json"}`)},
+			"name.json": {Data: []byte(`{"const": "` + name + `"}`)},
+		}
+
+		generator, err := chaff.ParseSchemaFS(fsys, "main.json", opts)
+		if err != nil || generator.Metadata.Errors.HasErrors() {
+			t.Fatalf("Failed to parse schema: %v %v", err, generator.Metadata.Errors.CollectErrors())
+		}
+
+		if result := generator.GenerateWithDefaults(); result != name {
+			t.Errorf("Expected the reused options to resolve within the given file system, got %v", result)
+		}
+	}
+
+	if opts.RelativeTo != "" || opts.DocumentFetchOptions.Fetchers != nil {
+		t.Errorf("Expected the given options to be left untouched, got %+v", opts)
+	}
+}
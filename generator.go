@@ -2,6 +2,7 @@ package chaff
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ryanolee/go-chaff/internal/util"
 	"github.com/ryanolee/go-chaff/rand"
@@ -16,9 +17,34 @@ type (
 	GeneratorOptions struct {
 		// The source of randomness to use for the given generation.
 		// Please note that some parts of the generators use different sources of randomness.
-		// ("regex" generation and "format" strings)
+		// ("regex" generation and the default FakerDataProvider)
 		Rand *rand.RandUtil `json:"-"`
 
+		// The source of realistic values for formats, "x-chaff" providers and lorem text (Default: FakerDataProvider)
+		// Use BuiltinDataProvider for values that are reproducible for a given Rand seed
+		DataProvider DataProvider `json:"-"`
+
+		// Locale used for text, names, addresses, internationalized formats ("idn-email", "idn-hostname", "iri")
+		// and date time offsets (e.g. "de", "ja-JP"). See SupportedLocales for the available locales.
+		// If no DataProvider is given the built-in word lists for the locale are used.
+		// Unsupported locales fall back to English (See IsSupportedLocale)
+		Locale string `json:"locale,omitempty" jsonschema:"title=Locale"`
+
+		// Clock used as "now" for time windows (Default: time.Now)
+		// Setting it (or a TimeWindow) generates "date-time", "date" and "time" values from Rand rather than the DataProvider
+		Now func() time.Time `json:"-"`
+
+		// Window of time "date-time", "date" and "time" values are generated within (Default: the Unix epoch until now)
+		// Narrowed further by any "formatMinimum" / "formatMaximum" given in the schema
+		TimeWindow TimeWindow `json:"timeWindow,omitempty" jsonschema:"title=Time Window"`
+
+		// Time zones to pick from when rendering "date-time" and "time" values. Repeat a zone to make it more likely.
+		// Takes precedence over the offsets of the Locale (Default: UTC)
+		TimeZones []*time.Location `json:"-"`
+
+		// Number of fractional second digits to include in "date-time" and "time" values (0 - 9, Default: 0)
+		FractionalSecondDigits int `json:"fractionalSecondDigits,omitempty" jsonschema:"title=Fractional Second Digits"`
+
 		// The default minimum number value
 		DefaultNumberMinimum int `json:"defaultNumberMinimum,omitempty" jsonschema:"title=Default Number Minimum"`
 
@@ -34,8 +60,8 @@ type (
 		// The default minimum array length
 		DefaultArrayMinItems int `json:"defaultArrayMinItems,omitempty" jsonschema:"title=Default Array Minimum Items"`
 
-		// The default maximum array length
-		// This will be set min + this inf the event a minimum value is set
+		// The default maximum array length for arrays without "maxItems" (If zero, 10 more than the minimum)
+		// This will be set min + this in the event it is below the minimum
 		DefaultArrayMaxItems int `json:"defaultArrayMaxItems,omitempty" jsonschema:"title=Default Array Maximum Items"`
 
 		// The default minimum object properties (Will be ignored if there are fewer properties available)
@@ -80,7 +106,25 @@ type (
 		// This is a hard cap on generation steps to prevent extremely long generation times
 		CutoffGenerationSteps int `json:"cutoffGenerationSteps,omitempty" jsonschema:"title=Cutoff Generation Steps"`
 
+		// Values to use for specific paths of the generated document instead of generating them.
+		// Keys are JSON pointers that can use "*" to match any single object key or array index
+		// (e.g. "/tenantId" or "/items/*/price"). Values are either constants or an OverrideFunc
+		// that can delegate to the generator that would otherwise have been used.
+		// Overrides are applied during generation so constraints such as "uniqueItems" and "oneOf" see the overridden values.
+		Overrides map[string]interface{} `json:"-"`
+
+		// Returns generated objects as OrderedObjects with declared properties in the order they appear in the schema
+		// followed by pattern and additional properties so the order is kept when the value is encoded as JSON or YAML
+		PreservePropertyOrder bool `json:"preservePropertyOrder,omitempty" jsonschema:"title=Preserve Property Order"`
+
 		overallComplexity int `json:"-"`
+
+		// Compiled overrides and the path of the value currently being generated (Used internally)
+		overrides    []compiledOverride
+		instancePath []string
+
+		// Provenance of generated values (Only set when generating through RootGenerator.GenerateWithTrace)
+		trace *traceState
 	}
 )
 
@@ -89,9 +133,31 @@ func withGeneratorOptionsDefaults(options GeneratorOptions) *GeneratorOptions {
 	if options.Rand == nil {
 		randUtil = rand.NewRandUtilFromTime()
 	}
+
+	dataProvider := options.DataProvider
+	if locale, ok := getLocale(options.Locale); dataProvider == nil && ok && locale.provider != nil {
+		dataProvider = *locale.provider
+	}
+
+	if dataProvider == nil {
+		dataProvider = FakerDataProvider{}
+	}
+
 	return &GeneratorOptions{
 		// General
-		Rand: randUtil,
+		Rand:         randUtil,
+		DataProvider: dataProvider,
+		Locale:       options.Locale,
+
+		// Time
+		Now:                    options.Now,
+		TimeWindow:             options.TimeWindow,
+		TimeZones:              options.TimeZones,
+		FractionalSecondDigits: util.MinInt(util.MaxInt(options.FractionalSecondDigits, 0), 9),
+
+		// Overrides
+		Overrides: options.Overrides,
+		overrides: compileOverrides(options.Overrides),
 
 		// Number
 		DefaultNumberMinimum: util.GetInt(options.DefaultNumberMinimum, 0),
@@ -103,12 +169,13 @@ func withGeneratorOptionsDefaults(options GeneratorOptions) *GeneratorOptions {
 
 		// Array
 		DefaultArrayMinItems: util.GetInt(options.DefaultArrayMinItems, 0),
-		DefaultArrayMaxItems: util.GetInt(options.DefaultArrayMaxItems, 10),
+		DefaultArrayMaxItems: options.DefaultArrayMaxItems,
 
 		// Object
 		DefaultObjectMinProperties: util.GetInt(options.DefaultObjectMinProperties, 0),
 		DefaultObjectMaxProperties: util.GetInt(options.DefaultObjectMaxProperties, 10),
 		SuppressFallbackValues:     util.GetBool(options.SuppressFallbackValues, true),
+		PreservePropertyOrder:      options.PreservePropertyOrder,
 
 		// References
 		BypassCyclicReferenceCheck: util.GetBool(options.BypassCyclicReferenceCheck, false),
@@ -0,0 +1,307 @@
+package chaff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ryanolee/go-chaff/internal/util"
+)
+
+type (
+	// Generation hints given through the "x-chaff" extension keyword.
+	// Example:
+	//
+	//	{
+	//	  "type": "string",
+	//	  "x-chaff": {"faker": "email"}
+	//	}
+	chaffHints struct {
+		// Name of a string provider to use in place of lorem text (e.g. "email", "name")
+		Faker *string `json:"faker,omitempty"`
+
+		// Relative weights for each "enum" value or "oneOf" / "anyOf" branch of the node
+		Weights *[]float64 `json:"weights,omitempty"`
+
+		// Probability [0-1] of the node being included when it is an optional object property
+		Probability *float64 `json:"probability,omitempty"`
+
+		// String template where "{{provider}}" placeholders are replaced with provider output
+		Template *string `json:"template,omitempty"`
+
+		// Generator option overrides applied to the node and everything below it
+		Options *hintOptions `json:"options,omitempty"`
+
+		// Error encountered while unmarshalling the hints. Reported during parsing
+		// rather than failing the whole schema
+		err error
+	}
+
+	// The subset of GeneratorOptions that can be overridden for a given subtree.
+	// Field names match the json names of their GeneratorOptions counterparts
+	hintOptions struct {
+		DefaultStringMinLength     *int `json:"defaultStringMinLength,omitempty"`
+		DefaultStringMaxLength     *int `json:"defaultStringMaxLength,omitempty"`
+		DefaultArrayMinItems       *int `json:"defaultArrayMinItems,omitempty"`
+		DefaultArrayMaxItems       *int `json:"defaultArrayMaxItems,omitempty"`
+		DefaultObjectMinProperties *int `json:"defaultObjectMinProperties,omitempty"`
+		DefaultObjectMaxProperties *int `json:"defaultObjectMaxProperties,omitempty"`
+	}
+
+	// Applies option overrides from "x-chaff" to everything generated beneath it
+	hintedGenerator struct {
+		internalGenerator Generator
+		options           hintOptions
+	}
+
+	stringProvider func(opts *GeneratorOptions) string
+)
+
+var templatePlaceholderRegex = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_-]+)\s*\}\}`)
+
+// Providers that can be referenced by name from "x-chaff" hints
+var stringProviders = map[string]stringProvider{
+	"word":          func(opts *GeneratorOptions) string { return opts.DataProvider.Word(opts.Rand) },
+	"sentence":      func(opts *GeneratorOptions) string { return opts.DataProvider.Sentence(opts.Rand) },
+	"paragraph":     func(opts *GeneratorOptions) string { return opts.DataProvider.Paragraph(opts.Rand) },
+	"name":          func(opts *GeneratorOptions) string { return opts.DataProvider.Name(opts.Rand) },
+	"firstName":     func(opts *GeneratorOptions) string { return opts.DataProvider.FirstName(opts.Rand) },
+	"lastName":      func(opts *GeneratorOptions) string { return opts.DataProvider.LastName(opts.Rand) },
+	"username":      func(opts *GeneratorOptions) string { return opts.DataProvider.Username(opts.Rand) },
+	"email":         func(opts *GeneratorOptions) string { return opts.DataProvider.Email(opts.Rand) },
+	"phone":         func(opts *GeneratorOptions) string { return opts.DataProvider.Phone(opts.Rand) },
+	"streetAddress": func(opts *GeneratorOptions) string { return opts.DataProvider.StreetAddress(opts.Rand) },
+	"city":          func(opts *GeneratorOptions) string { return opts.DataProvider.City(opts.Rand) },
+	"postalCode":    func(opts *GeneratorOptions) string { return opts.DataProvider.PostalCode(opts.Rand) },
+	"country":       func(opts *GeneratorOptions) string { return opts.DataProvider.Country(opts.Rand) },
+	"domainName":    func(opts *GeneratorOptions) string { return opts.DataProvider.DomainName(opts.Rand) },
+	"url":           func(opts *GeneratorOptions) string { return opts.DataProvider.URL(opts.Rand) },
+	"ipv4":          func(opts *GeneratorOptions) string { return opts.DataProvider.IPv4(opts.Rand) },
+	"ipv6":          func(opts *GeneratorOptions) string { return opts.DataProvider.IPv6(opts.Rand) },
+	"uuid":          func(opts *GeneratorOptions) string { return opts.DataProvider.UUID(opts.Rand) },
+	"dateTime":      func(opts *GeneratorOptions) string { return generateFormat(formatDateTime, opts) },
+	"date":          func(opts *GeneratorOptions) string { return generateFormat(formatDate, opts) },
+	"time":          func(opts *GeneratorOptions) string { return generateFormat(formatTime, opts) },
+}
+
+func (h *chaffHints) UnmarshalJSON(data []byte) error {
+	type chaffHintsAlias chaffHints
+	var alias chaffHintsAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		// Malformed hints should not prevent the rest of the schema from being parsed
+		*h = chaffHints{err: fmt.Errorf("invalid x-chaff value: %w", err)}
+		return nil
+	}
+
+	*h = chaffHints(alias)
+	return nil
+}
+
+// Validates the hints for a node, reporting any problems to the error collection.
+// Returns a copy of the hints with any invalid entries removed.
+func parseHints(node schemaNode, metadata *parserMetadata) *chaffHints {
+	if node.XChaff == nil {
+		return nil
+	}
+
+	hints := *node.XChaff
+	if hints.err != nil {
+		metadata.Errors.AddErrorWithSubpath("/x-chaff", hints.err)
+		return nil
+	}
+
+	if hints.Faker != nil {
+		if _, ok := stringProviders[*hints.Faker]; !ok {
+			metadata.Errors.AddErrorWithSubpath("/x-chaff/faker", fmt.Errorf("unknown faker provider '%s' (known providers: %s)", *hints.Faker, strings.Join(getStringProviderNames(), ", ")))
+			hints.Faker = nil
+		}
+	}
+
+	if hints.Weights != nil {
+		if err := validateWeights(*hints.Weights); err != nil {
+			metadata.Errors.AddErrorWithSubpath("/x-chaff/weights", err)
+			hints.Weights = nil
+		}
+	}
+
+	if hints.Probability != nil && (*hints.Probability < 0 || *hints.Probability > 1) {
+		metadata.Errors.AddErrorWithSubpath("/x-chaff/probability", fmt.Errorf("probability must be between 0 and 1 (given: %f)", *hints.Probability))
+		hints.Probability = nil
+	}
+
+	if hints.Template != nil {
+		for _, match := range templatePlaceholderRegex.FindAllStringSubmatch(*hints.Template, -1) {
+			if _, ok := stringProviders[match[1]]; !ok {
+				metadata.Errors.AddErrorWithSubpath("/x-chaff/template", fmt.Errorf("unknown provider '%s' used in template", match[1]))
+				hints.Template = nil
+				break
+			}
+		}
+	}
+
+	if hints.Options != nil {
+		if err := hints.Options.validate(); err != nil {
+			metadata.Errors.AddErrorWithSubpath("/x-chaff/options", err)
+			hints.Options = nil
+		}
+	}
+
+	return &hints
+}
+
+func validateWeights(weights []float64) error {
+	total := 0.0
+	for i, weight := range weights {
+		if weight < 0 {
+			return fmt.Errorf("weights cannot be negative (weights[%d]: %f)", i, weight)
+		}
+		total += weight
+	}
+
+	if total <= 0 {
+		return errors.New("at least one weight must be greater than 0")
+	}
+
+	return nil
+}
+
+// Returns the weights from the given hints if they line up with the number of choices available
+func getHintWeights(hints *chaffHints, choices int, metadata *parserMetadata) []float64 {
+	if hints == nil || hints.Weights == nil {
+		return nil
+	}
+
+	if len(*hints.Weights) != choices {
+		metadata.Errors.AddErrorWithSubpath("/x-chaff/weights", fmt.Errorf("number of weights (%d) does not match the number of choices (%d)", len(*hints.Weights), choices))
+		return nil
+	}
+
+	return *hints.Weights
+}
+
+func getStringProviderNames() []string {
+	names := util.MapKeysToStringSlice(&stringProviders)
+	sort.Strings(names)
+	return names
+}
+
+func renderTemplate(template string, opts *GeneratorOptions) string {
+	return templatePlaceholderRegex.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := templatePlaceholderRegex.FindStringSubmatch(placeholder)[1]
+		provider, ok := stringProviders[name]
+		if !ok {
+			return placeholder
+		}
+
+		return provider(opts)
+	})
+}
+
+// Merges two sets of hints. Values set on the right hand side take precedence
+func mergeHints(left *chaffHints, right *chaffHints) *chaffHints {
+	if left == nil {
+		return right
+	}
+
+	if right == nil {
+		return left
+	}
+
+	merged := *left
+	merged.Faker = util.GetPtr(right.Faker, left.Faker)
+	merged.Weights = util.GetPtr(right.Weights, left.Weights)
+	merged.Probability = util.GetPtr(right.Probability, left.Probability)
+	merged.Template = util.GetPtr(right.Template, left.Template)
+	if right.err != nil {
+		merged.err = right.err
+	}
+
+	if left.Options != nil && right.Options != nil {
+		options := mergeHintOptions(*left.Options, *right.Options)
+		merged.Options = &options
+	} else {
+		merged.Options = util.GetPtr(right.Options, left.Options)
+	}
+
+	return &merged
+}
+
+func mergeHintOptions(left hintOptions, right hintOptions) hintOptions {
+	return hintOptions{
+		DefaultStringMinLength:     util.GetPtr(right.DefaultStringMinLength, left.DefaultStringMinLength),
+		DefaultStringMaxLength:     util.GetPtr(right.DefaultStringMaxLength, left.DefaultStringMaxLength),
+		DefaultArrayMinItems:       util.GetPtr(right.DefaultArrayMinItems, left.DefaultArrayMinItems),
+		DefaultArrayMaxItems:       util.GetPtr(right.DefaultArrayMaxItems, left.DefaultArrayMaxItems),
+		DefaultObjectMinProperties: util.GetPtr(right.DefaultObjectMinProperties, left.DefaultObjectMinProperties),
+		DefaultObjectMaxProperties: util.GetPtr(right.DefaultObjectMaxProperties, left.DefaultObjectMaxProperties),
+	}
+}
+
+func (o hintOptions) validate() error {
+	values := map[string]*int{
+		"defaultStringMinLength":     o.DefaultStringMinLength,
+		"defaultStringMaxLength":     o.DefaultStringMaxLength,
+		"defaultArrayMinItems":       o.DefaultArrayMinItems,
+		"defaultArrayMaxItems":       o.DefaultArrayMaxItems,
+		"defaultObjectMinProperties": o.DefaultObjectMinProperties,
+		"defaultObjectMaxProperties": o.DefaultObjectMaxProperties,
+	}
+
+	for _, name := range util.MapKeysToStringSlice(&values) {
+		if value := values[name]; value != nil && *value < 0 {
+			return fmt.Errorf("%s cannot be negative (given: %d)", name, *value)
+		}
+	}
+
+	bounds := [][2]*int{
+		{o.DefaultStringMinLength, o.DefaultStringMaxLength},
+		{o.DefaultArrayMinItems, o.DefaultArrayMaxItems},
+		{o.DefaultObjectMinProperties, o.DefaultObjectMaxProperties},
+	}
+
+	for _, bound := range bounds {
+		if bound[0] != nil && bound[1] != nil && *bound[0] > *bound[1] {
+			return fmt.Errorf("minimum option override (%d) cannot be greater than the maximum (%d)", *bound[0], *bound[1])
+		}
+	}
+
+	return nil
+}
+
+// Overrides the passed generator options, returning a function that restores them
+func (o hintOptions) apply(opts *GeneratorOptions) func() {
+	previous := *opts
+	opts.DefaultStringMinLength = util.GetZeroIfNil(o.DefaultStringMinLength, opts.DefaultStringMinLength)
+	opts.DefaultStringMaxLength = util.GetZeroIfNil(o.DefaultStringMaxLength, opts.DefaultStringMaxLength)
+	opts.DefaultArrayMinItems = util.GetZeroIfNil(o.DefaultArrayMinItems, opts.DefaultArrayMinItems)
+	opts.DefaultArrayMaxItems = util.GetZeroIfNil(o.DefaultArrayMaxItems, opts.DefaultArrayMaxItems)
+	opts.DefaultObjectMinProperties = util.GetZeroIfNil(o.DefaultObjectMinProperties, opts.DefaultObjectMinProperties)
+	opts.DefaultObjectMaxProperties = util.GetZeroIfNil(o.DefaultObjectMaxProperties, opts.DefaultObjectMaxProperties)
+
+	return func() {
+		opts.DefaultStringMinLength = previous.DefaultStringMinLength
+		opts.DefaultStringMaxLength = previous.DefaultStringMaxLength
+		opts.DefaultArrayMinItems = previous.DefaultArrayMinItems
+		opts.DefaultArrayMaxItems = previous.DefaultArrayMaxItems
+		opts.DefaultObjectMinProperties = previous.DefaultObjectMinProperties
+		opts.DefaultObjectMaxProperties = previous.DefaultObjectMaxProperties
+	}
+}
+
+func (g hintedGenerator) Generate(opts *GeneratorOptions) interface{} {
+	restore := g.options.apply(opts)
+	defer restore()
+
+	return g.internalGenerator.Generate(opts)
+}
+
+func (g hintedGenerator) String() string {
+	return fmt.Sprintf("HintedGenerator{%s}", g.internalGenerator)
+}
+
+func (g hintedGenerator) describe(node *GeneratorNode) {
+	node.describe(g.internalGenerator)
+}
@@ -0,0 +1,100 @@
+package chaff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+	test "github.com/ryanolee/go-chaff/internal/test_utils"
+)
+
+func TestHints(t *testing.T) {
+	t.Parallel()
+	test.TestJsonSchemaDir(t, "test_data/hints", 100)
+}
+
+func TestHintsWeightsAndOptions(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{
+		"type": "object",
+		"properties": {
+			"enum": {"enum": ["a", "b"], "x-chaff": {"weights": [1, 0]}},
+			"items": {
+				"type": "array",
+				"items": {"type": "string"},
+				"x-chaff": {"options": {"defaultArrayMaxItems": 2, "defaultStringMaxLength": 4}}
+			}
+		},
+		"required": ["enum", "items"]
+	}`)
+
+	if err != nil || generator.Metadata.Errors.HasErrors() {
+		t.Fatalf("Failed to parse schema: %v %v", err, generator.Metadata.Errors.CollectErrors())
+	}
+
+	for i := 0; i < 100; i++ {
+		value := generator.GenerateWithDefaults().(map[string]interface{})
+		if value["enum"] != "a" {
+			t.Fatalf("Expected enum value with a weight of 0 to never be generated, got %v", value["enum"])
+		}
+
+		items := value["items"].([]interface{})
+		if len(items) > 2 {
+			t.Fatalf("Expected at most 2 items due to x-chaff options, got %d", len(items))
+		}
+
+		for _, item := range items {
+			if len(item.(string)) > 4 {
+				t.Fatalf("Expected strings of at most 4 characters due to x-chaff options, got %q", item)
+			}
+		}
+	}
+}
+
+func TestHintsInvalid(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{
+		"type": "object",
+		"properties": {
+			"faker": {"type": "string", "x-chaff": {"faker": "notAProvider"}},
+			"weights": {"enum": [1, 2], "x-chaff": {"weights": [1, 2, 3]}},
+			"negativeWeights": {"enum": [1, 2], "x-chaff": {"weights": [-1, 2]}},
+			"probability": {"type": "string", "x-chaff": {"probability": 2}},
+			"template": {"type": "string", "x-chaff": {"template": "{{notAProvider}}"}},
+			"options": {"type": "string", "x-chaff": {"options": {"defaultStringMinLength": 5, "defaultStringMaxLength": 1}}},
+			"malformed": {"type": "string", "x-chaff": "name"}
+		}
+	}`)
+
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	expectedPaths := []string{
+		"/properties/faker/x-chaff/faker",
+		"/properties/weights/x-chaff/weights",
+		"/properties/negativeWeights/x-chaff/weights",
+		"/properties/probability/x-chaff/probability",
+		"/properties/template/x-chaff/template",
+		"/properties/options/x-chaff/options",
+		"/properties/malformed/x-chaff",
+	}
+
+	errors := generator.Metadata.Errors.CollectErrors()
+	for _, expectedPath := range expectedPaths {
+		found := false
+		for path := range errors {
+			if strings.HasSuffix(path, expectedPath) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			t.Errorf("Expected an error to be reported at %s, got %v", expectedPath, errors)
+		}
+	}
+
+	// Invalid hints are dropped so generation still works
+	generator.GenerateWithDefaults()
+}
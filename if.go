@@ -3,6 +3,7 @@ package chaff
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/thoas/go-funk"
@@ -13,6 +14,11 @@ type (
 		conditionFunc func(value any) bool
 		thenGenerator Generator
 		elseGenerator Generator
+
+		// Where the "if" was defined and its index amongst the other "if"s of the node (Used for tracing)
+		document   string
+		schemaPath string
+		index      int
 	}
 
 	multipleIfConstraints struct {
@@ -67,6 +73,7 @@ func parseIf(node schemaNode, metadata *parserMetadata) (Generator, error) {
 			continue
 		}
 
+		compiled.index = len(constraints)
 		constraints = append(constraints, compiled)
 	}
 
@@ -135,10 +142,12 @@ func (s ifStatement) Compile(node schemaNode, metadata *parserMetadata, field st
 
 	return ifConstraint{
 		conditionFunc: func(value any) bool {
-			return ifSchema.Validate(value) == nil
+			return ifSchema.Validate(unorderedValue(value)) == nil
 		},
 		thenGenerator: thenGenerator,
 		elseGenerator: elseGenerator,
+		document:      metadata.DocumentResolver.GetDocumentIdCurrentlyBeingParsed(),
+		schemaPath:    metadata.ReferenceHandler.CurrentPath,
 	}, nil
 }
 
@@ -151,22 +160,26 @@ func (g ifConstraint) AttemptToSatisfyIfStatement(generatorOptions *GeneratorOpt
 		if g.thenGenerator == nil {
 			// Per JSON Schema: if the condition matches and there is no "then",
 			// no additional constraints apply — the value is valid as-is.
+			traceCondition(generatorOptions, g, "then")
 			return generatedValue, true
 		}
 
 		thenValue := g.thenGenerator.Generate(generatorOptions)
 		if g.conditionFunc(thenValue) {
+			traceCondition(generatorOptions, g, "then")
 			return thenValue, true
 		}
 	} else {
 		if g.elseGenerator == nil {
 			// Per JSON Schema: if the condition does not match and there is no "else",
 			// no additional constraints apply — the value is valid as-is.
+			traceCondition(generatorOptions, g, "else")
 			return generatedValue, true
 		}
 
 		elseValue := g.elseGenerator.Generate(generatorOptions)
 		if !g.conditionFunc(elseValue) {
+			traceCondition(generatorOptions, g, "else")
 			return elseValue, true
 		}
 	}
@@ -180,6 +193,7 @@ func (g ifConstraint) Apply(generator Generator, generatorOptions *GeneratorOpti
 	maxAttempts := generatorOptions.ScaledRetryBudget(generatorOptions.MaximumIfAttempts)
 	for i := 0; i < maxAttempts; i++ {
 		generatorOptions.overallComplexity++
+		traceIfAttempt(generatorOptions)
 		if satisfiedValue, satisfied := g.AttemptToSatisfyIfStatement(generatorOptions, generatedValue, false); satisfied {
 			return satisfiedValue
 		}
@@ -187,6 +201,7 @@ func (g ifConstraint) Apply(generator Generator, generatorOptions *GeneratorOpti
 		generatedValue = generator.Generate(generatorOptions)
 	}
 
+	traceUnsatisfied(generatorOptions)
 	return fmt.Sprintf("Failed to generate a valid value for the following if constraint after %d attempts", maxAttempts)
 }
 
@@ -200,6 +215,7 @@ func (g multipleIfConstraints) Apply(generator Generator, generatorOptions *Gene
 	// Brute force: regenerate until all constraints are satisfied
 	for i := 0; i < maxAttempts; i++ {
 		generatorOptions.overallComplexity++
+		traceIfAttempt(generatorOptions)
 
 		currentValue := generatedValue
 		allSatisfied := true
@@ -222,6 +238,7 @@ func (g multipleIfConstraints) Apply(generator Generator, generatorOptions *Gene
 		generatedValue = generator.Generate(generatorOptions)
 	}
 
+	traceUnsatisfied(generatorOptions)
 	return fmt.Sprintf("Failed to generate a valid value for the following if constraints after %d attempts: [%s]",
 		generatorOptions.MaximumIfAttempts,
 		g,
@@ -233,3 +250,12 @@ func (g multipleIfConstraints) String() string {
 		return c.String()
 	}).([]string), ", "))
 }
+
+func (g multipleIfConstraints) describeConstraint(node *GeneratorNode) {
+	for i, constraint := range g.constraints {
+		key := strconv.Itoa(i)
+		node.Constraints = append(node.Constraints, fmt.Sprintf("if: %s", key))
+		node.addChild("then", key, constraint.thenGenerator)
+		node.addChild("else", key, constraint.elseGenerator)
+	}
+}
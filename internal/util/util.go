@@ -187,8 +187,8 @@ func countDecimalPlaces(f float64) float64 {
 	return float64(len(s[1]))
 }
 
-// FindHcf finds the highest common factor between two float64 pointers
-func FindHcf(a *float64, b *float64) *float64 {
+// FindLcm finds the least common multiple of two float64 pointers
+func FindLcm(a *float64, b *float64) *float64 {
 	result := new(float64)
 	// Return one if the other is not set
 	if a == nil || *a == 0 {
@@ -210,11 +210,16 @@ func FindHcf(a *float64, b *float64) *float64 {
 
 	// Normalise both to integers
 	scale := math.Pow(10, float64(math.Max(countDecimalPlaces(*a), countDecimalPlaces(*b))))
-	intA := *a * scale
-	intB := *b * scale
+	intA := math.Round(*a * scale)
+	intB := math.Round(*b * scale)
 
-	// Otherwise multiply them
-	*result = (intA * intB) / scale
+	// Otherwise divide their product by their greatest common divisor
+	gcd, other := intA, intB
+	for other != 0 {
+		gcd, other = other, math.Mod(gcd, other)
+	}
+
+	*result = (intA / gcd * intB) / scale
 	return result
 }
 
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindLcm(t *testing.T) {
+	ptr := func(value float64) *float64 { return &value }
+	testCases := []struct {
+		a        *float64
+		b        *float64
+		expected *float64
+	}{
+		{nil, nil, nil},
+		{nil, ptr(3.0), ptr(3.0)},
+		{ptr(0.0), ptr(3.0), ptr(3.0)},
+		{ptr(3.0), nil, ptr(3.0)},
+		{ptr(4.0), ptr(8.0), ptr(8.0)},
+		{ptr(4.0), ptr(6.0), ptr(12.0)},
+		{ptr(7.0), ptr(5.0), ptr(35.0)},
+		{ptr(0.5), ptr(0.3), ptr(1.5)},
+		{ptr(0.1), ptr(0.25), ptr(0.5)},
+		{ptr(1.5), ptr(2.5), ptr(7.5)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprintf("TestCase a: %v b: %v", testCase.a, testCase.b), func(t *testing.T) {
+			result := FindLcm(testCase.a, testCase.b)
+			if testCase.expected == nil {
+				assert.Nil(t, result)
+				return
+			}
+
+			assert.NotNil(t, result)
+			assert.InDelta(t, *testCase.expected, *result, 1e-9)
+		})
+	}
+}
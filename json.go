@@ -1,7 +1,9 @@
 package chaff
 
 import (
+	"bytes"
 	"encoding/json"
+	"sort"
 )
 
 type (
@@ -11,6 +13,12 @@ type (
 		MultipleTypes []string
 	}
 
+	// Used to record the order "properties" are declared in as maps do not keep it
+	orderedSchemaProperties struct {
+		Properties *map[string]schemaNode
+		Order      []string
+	}
+
 	// Used to handle the fact that "items" can be a schema node or an array of schema nodes
 	itemsData struct {
 		Node                    *schemaNode
@@ -147,11 +155,20 @@ func (s *schemaNodeOrFalse) MarshalJSON() ([]byte, error) {
 // below detects when "const" is explicitly present so the pointer is non-nil.
 func (s *schemaNode) UnmarshalJSON(data []byte) error {
 	type schemaNodeAlias schemaNode
-	var alias schemaNodeAlias
+	var alias struct {
+		schemaNodeAlias
+
+		// Takes the place of schemaNode.Properties to record the order they are declared in
+		Properties *orderedSchemaProperties `json:"properties,omitempty"`
+	}
 	if err := json.Unmarshal(data, &alias); err != nil {
 		return err
 	}
-	*s = schemaNode(alias)
+	*s = schemaNode(alias.schemaNodeAlias)
+	if alias.Properties != nil {
+		s.Properties = alias.Properties.Properties
+		s.propertyOrder = alias.Properties.Order
+	}
 
 	// Only ambiguous when the standard pass left Const nil — a non-null
 	// const value is already correctly populated.
@@ -168,3 +185,99 @@ func (s *schemaNode) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// Decodes "properties" recording the order they are declared in as it goes rather than parsing them again
+func (p *orderedSchemaProperties) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		// Anything but an object is left to the standard decoder to report
+		return json.Unmarshal(data, &p.Properties)
+	}
+
+	properties := map[string]schemaNode{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		key, _ := token.(string)
+		var node schemaNode
+		if err := decoder.Decode(&node); err != nil {
+			return err
+		}
+
+		if _, exists := properties[key]; !exists {
+			p.Order = append(p.Order, key)
+		}
+
+		properties[key] = node
+	}
+
+	p.Properties = &properties
+	return nil
+}
+
+// Writes "properties" in the order they were declared in so the order survives the node being
+// marshalled and unmarshalled again (e.g. when references are rewritten)
+func (s schemaNode) MarshalJSON() ([]byte, error) {
+	type schemaNodeAlias schemaNode
+	data, err := json.Marshal(schemaNodeAlias(s))
+	if err != nil || s.Properties == nil || len(*s.Properties) < 2 || len(s.propertyOrder) == 0 {
+		return data, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	var properties map[string]json.RawMessage
+	if err := json.Unmarshal(raw["properties"], &properties); err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range getPropertyOrder(*s.Properties, s.propertyOrder) {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(encodedKey)
+		buffer.WriteByte(':')
+		buffer.Write(properties[key])
+	}
+
+	buffer.WriteByte('}')
+	raw["properties"] = buffer.Bytes()
+	return json.Marshal(raw)
+}
+
+// Returns the keys of the given properties in the given order. Keys missing from the order
+// (e.g. added when merging) follow in alphabetical order
+func getPropertyOrder[T any](properties map[string]T, order []string) []string {
+	keys := make([]string, 0, len(properties))
+	seen := make(map[string]bool, len(properties))
+	for _, key := range order {
+		if _, ok := properties[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+
+	remaining := []string{}
+	for key := range properties {
+		if !seen[key] {
+			remaining = append(remaining, key)
+		}
+	}
+
+	sort.Strings(remaining)
+	return append(keys, remaining...)
+}
@@ -0,0 +1,180 @@
+package chaff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Keywords that are only annotations and are deliberately not used for generation
+var annotationKeywords = []string{
+	"$schema", "$comment", "$vocabulary",
+	"title", "description", "default", "examples", "deprecated", "readOnly", "writeOnly",
+	"contentMediaType", "contentEncoding", "contentSchema",
+}
+
+// Standard JSON Schema keywords that are not supported and are ignored during generation
+var unsupportedKeywords = []string{
+	"propertyNames", "unevaluatedProperties", "dependencies",
+	"$anchor", "$dynamicAnchor", "$dynamicRef", "$recursiveRef", "$recursiveAnchor",
+}
+
+// Keywords whose values are a map of sub-schemas
+var schemaMapKeywords = []string{"properties", "patternProperties", "$defs", "definitions", "dependentSchemas"}
+
+// Keywords whose values are a sub-schema or an array of sub-schemas
+var subSchemaKeywords = []string{
+	"not", "if", "then", "else", "contains", "items", "additionalProperties", "additionalItems", "unevaluatedItems",
+	"propertyNames", "unevaluatedProperties", "allOf", "anyOf", "oneOf", "prefixItems",
+}
+
+// Returns the keywords that are either understood by the parser, annotations or standard JSON Schema keywords.
+// Keywords used for generation and annotations map to false, standard keywords that are ignored map to true
+func knownKeywords(allowed ...string) map[string]bool {
+	known := map[string]bool{}
+	nodeType := reflect.TypeOf(schemaNode{})
+	for i := 0; i < nodeType.NumField(); i++ {
+		name := strings.Split(nodeType.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			known[name] = false
+		}
+	}
+
+	for _, keyword := range annotationKeywords {
+		known[keyword] = false
+	}
+
+	for _, keyword := range unsupportedKeywords {
+		known[keyword] = true
+	}
+
+	for _, keyword := range allowed {
+		known[keyword] = false
+	}
+
+	return known
+}
+
+// Returns diagnostics for every keyword in the given document source (and its sub-schemas) that is ignored
+// during generation. Unknown keywords are reported as warnings, unsupported standard keywords as unsupported.
+// Extension keywords prefixed with "x-" are never reported
+func findIgnoredKeywords(document string, source []byte, known map[string]bool) Diagnostics {
+	diagnostics := Diagnostics{}
+
+	data, err := decodeSchemaSource(document, source)
+	if err != nil {
+		return diagnostics
+	}
+
+	var node interface{}
+	if err := json.Unmarshal(data, &node); err != nil {
+		return diagnostics
+	}
+
+	walkIgnoredKeywords(node, "#", known, func(pointer string, keyword string) {
+		diagnostic := Diagnostic{
+			Severity: DiagnosticSeverityWarning,
+			Code:     DiagnosticCodeUnknownKeyword,
+			Message:  fmt.Sprintf("unknown keyword '%s' is ignored", keyword),
+			Document: document,
+			Pointer:  pointer + "/" + escapeJsonPointerSegment(keyword),
+		}
+
+		if known[keyword] {
+			diagnostic.Severity = DiagnosticSeverityUnsupported
+			diagnostic.Code = DiagnosticCodeUnsupportedKeyword
+			diagnostic.Message = fmt.Sprintf("keyword '%s' is not supported and is ignored", keyword)
+		}
+
+		diagnostic.Line, diagnostic.Column = locateJsonPointer(source, diagnostic.Pointer)
+		diagnostics = append(diagnostics, diagnostic)
+	})
+
+	return diagnostics
+}
+
+// Calls report for every keyword of the schema (and its sub-schemas) that is ignored during generation
+func walkIgnoredKeywords(node interface{}, pointer string, known map[string]bool, report func(pointer string, keyword string)) {
+	object, ok := node.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	keywords := make([]string, 0, len(object))
+	for keyword := range object {
+		keywords = append(keywords, keyword)
+	}
+
+	sort.Strings(keywords)
+	for _, keyword := range keywords {
+		ignored, isKnown := known[keyword]
+		if (!isKnown && !strings.HasPrefix(keyword, "x-")) || ignored {
+			report(pointer, keyword)
+		}
+	}
+
+	for _, keyword := range schemaMapKeywords {
+		schemas, ok := object[keyword].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		names := make([]string, 0, len(schemas))
+		for name := range schemas {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+		for _, name := range names {
+			walkIgnoredKeywords(schemas[name], fmt.Sprintf("%s/%s/%s", pointer, keyword, escapeJsonPointerSegment(name)), known, report)
+		}
+	}
+
+	for _, keyword := range subSchemaKeywords {
+		switch value := object[keyword].(type) {
+		case map[string]interface{}:
+			walkIgnoredKeywords(value, fmt.Sprintf("%s/%s", pointer, keyword), known, report)
+		case []interface{}:
+			for i, schema := range value {
+				walkIgnoredKeywords(schema, fmt.Sprintf("%s/%s/%d", pointer, keyword, i), known, report)
+			}
+		}
+	}
+}
+
+// Records every keyword ignored during generation in the parsed documents as a diagnostic (See ParserOptions.Strict).
+// Returns an error listing them if parsing should fail because of them
+func checkStrictKeywords(metadata *parserMetadata) error {
+	opts := metadata.ParserOptions
+	if !opts.Strict {
+		return nil
+	}
+
+	known := knownKeywords(opts.StrictAllowedKeywords...)
+	found := []string{}
+	documents := make([]string, 0, len(metadata.DocumentResolver.sources))
+	for document := range metadata.DocumentResolver.sources {
+		documents = append(documents, document)
+	}
+
+	sort.Strings(documents)
+	for _, document := range documents {
+		for _, diagnostic := range findIgnoredKeywords(document, metadata.DocumentResolver.sources[document], known) {
+			if _, ok := metadata.Errors.Errors[document]; !ok {
+				metadata.Errors.Errors[document] = map[string]error{}
+			}
+
+			metadata.Errors.Errors[document][diagnostic.Pointer] = newDiagnosticError(diagnostic.Severity, diagnostic.Code, errors.New(diagnostic.Message))
+			found = append(found, fmt.Sprintf("%s%s", document, diagnostic.Pointer))
+		}
+	}
+
+	if len(found) > 0 && opts.StrictFailOnUnknownKeywords {
+		return fmt.Errorf("strict mode: found %d unrecognised keywords: %s", len(found), strings.Join(found, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package chaff
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ryanolee/go-chaff/rand"
+)
+
+type (
+	// Options for linting a schema
+	LintOptions struct {
+		// Number of values to generate when looking for dead "oneOf" branches and unreachable "if" branches.
+		// If zero, defaults to 100. Sampling can be disabled by setting this to a negative value
+		Samples int
+
+		// Options used to generate the samples. Unless a source of randomness is given
+		// a fixed seed is used so results are reproducible between runs
+		GeneratorOptions GeneratorOptions
+	}
+
+	// Uniquely identifies a schema node across documents
+	lintLocation struct {
+		document string
+		path     string
+	}
+
+	// What the samples revealed about a schema node
+	lintSampleStats struct {
+		reached     int
+		unsatisfied int
+		branches    map[int]bool
+		conditions  map[string]bool
+	}
+)
+
+const defaultLintSamples = 100
+
+// Lints the schema reporting parse diagnostics, keywords that are ignored during generation and, by sampling
+// generated values, "oneOf" branches that are never generated and "if" branches that are never taken.
+// Sampling can only show branches are very unlikely to be generated rather than prove they can never be
+// so such findings are reported as warnings.
+func (g RootGenerator) Lint(opts *LintOptions) Diagnostics {
+	if opts == nil {
+		opts = &LintOptions{}
+	}
+
+	diagnostics := g.Diagnostics()
+	if g.Metadata == nil {
+		return diagnostics
+	}
+
+	diagnostics = append(diagnostics, g.lintKeywords()...)
+
+	samples := opts.Samples
+	if samples == 0 {
+		samples = defaultLintSamples
+	}
+
+	if samples > 0 {
+		diagnostics = append(diagnostics, g.lintSamples(opts.GeneratorOptions, samples)...)
+	}
+
+	sort.Sort(diagnostics)
+	return diagnostics
+}
+
+// Reports keywords in the source of each document that are not used for generation
+func (g RootGenerator) lintKeywords() Diagnostics {
+	diagnostics := Diagnostics{}
+
+	// Already reported as part of the parse diagnostics
+	if g.Metadata.ParserOptions.Strict {
+		return diagnostics
+	}
+
+	known := knownKeywords(g.Metadata.ParserOptions.StrictAllowedKeywords...)
+	for document, source := range g.Metadata.DocumentResolver.sources {
+		diagnostics = append(diagnostics, findIgnoredKeywords(document, source, known)...)
+	}
+
+	return diagnostics
+}
+
+// Generates samples and reports "oneOf" / "if" branches that none of them used along with
+// schema nodes whose constraints could not be satisfied in any sample
+func (g RootGenerator) lintSamples(generatorOptions GeneratorOptions, samples int) Diagnostics {
+	if generatorOptions.Rand == nil {
+		generatorOptions.Rand = rand.NewRandUtil(0)
+	}
+
+	stats := map[lintLocation]*lintSampleStats{}
+	getStats := func(location lintLocation) *lintSampleStats {
+		if _, ok := stats[location]; !ok {
+			stats[location] = &lintSampleStats{branches: map[int]bool{}, conditions: map[string]bool{}}
+		}
+
+		return stats[location]
+	}
+
+	for i := 0; i < samples; i++ {
+		_, trace := g.GenerateWithTrace(&generatorOptions)
+		for _, entry := range trace {
+			location := getStats(lintLocation{entry.Document, entry.SchemaPath})
+			location.reached++
+			if entry.Unsatisfied {
+				location.unsatisfied++
+			}
+
+			for _, branch := range entry.Branches {
+				if branch.Keyword == GeneratorKindOneOf {
+					getStats(lintLocation{branch.Document, branch.SchemaPath}).branches[branch.Index] = true
+				}
+			}
+
+			for _, condition := range entry.Conditions {
+				getStats(lintLocation{condition.Document, condition.SchemaPath}).conditions[fmt.Sprintf("%s/%d", condition.Branch, condition.Index)] = true
+			}
+		}
+	}
+
+	diagnostics := Diagnostics{}
+	newDiagnostic := func(code DiagnosticCode, document string, pointer string, message string) Diagnostic {
+		diagnostic := Diagnostic{
+			Severity: DiagnosticSeverityWarning,
+			Code:     code,
+			Message:  message,
+			Document: document,
+			Pointer:  pointer,
+		}
+
+		if source, ok := g.Metadata.DocumentResolver.sources[document]; ok {
+			diagnostic.Line, diagnostic.Column = locateJsonPointer(source, pointer)
+		}
+
+		return diagnostic
+	}
+
+	// Values are traced to the innermost schema node that produced them
+	for location, stat := range stats {
+		if stat.reached > 0 && stat.unsatisfied == stat.reached {
+			diagnostic := newDiagnostic(DiagnosticCodeUnsatisfiable, location.document, location.path, fmt.Sprintf("constraints could not be satisfied in any of the %d values generated from this schema", stat.reached))
+			diagnostic.Severity = DiagnosticSeverityError
+			diagnostics = append(diagnostics, diagnostic)
+		}
+	}
+
+	g.Tree().Walk(func(node *GeneratorNode) bool {
+		location, ok := stats[lintLocation{node.Document, node.SchemaPath}]
+		if !ok {
+			return true
+		}
+
+		for _, child := range node.Children {
+			index, err := strconv.Atoi(child.Key)
+			if err != nil {
+				continue
+			}
+
+			switch {
+			case node.Kind == GeneratorKindOneOf && child.Relation == "oneOf" && len(location.branches) > 0 && !location.branches[index]:
+				diagnostics = append(diagnostics, newDiagnostic(
+					DiagnosticCodeDeadOneOfBranch,
+					node.Document,
+					fmt.Sprintf("%s/oneOf/%d", node.SchemaPath, index),
+					fmt.Sprintf("oneOf branch %d was never generated in %d samples. It may be unsatisfiable or always match another branch", index, samples),
+				))
+			case (child.Relation == "then" || child.Relation == "else") && len(location.conditions) > 0 && !location.conditions[fmt.Sprintf("%s/%d", child.Relation, index)]:
+				diagnostics = append(diagnostics, newDiagnostic(
+					DiagnosticCodeUnreachableIfBranch,
+					node.Document,
+					fmt.Sprintf("%s/%s", node.SchemaPath, child.Relation),
+					fmt.Sprintf("'%s' branch of if %d was never taken in %d samples", child.Relation, index, samples),
+				))
+			}
+		}
+
+		return true
+	})
+
+	return diagnostics
+}
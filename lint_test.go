@@ -0,0 +1,171 @@
+package chaff_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+func lintSchema(t *testing.T, schema string, opts *chaff.LintOptions) chaff.Diagnostics {
+	generator, err := chaff.ParseSchemaStringWithDefaults(schema)
+	if err != nil && generator.Metadata == nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	return generator.Lint(opts)
+}
+
+func findDiagnostic(diagnostics chaff.Diagnostics, code chaff.DiagnosticCode, pointer string) *chaff.Diagnostic {
+	for i, diagnostic := range diagnostics {
+		if diagnostic.Code == code && diagnostic.Pointer == pointer {
+			return &diagnostics[i]
+		}
+	}
+
+	return nil
+}
+
+func TestLintDeadOneOfBranch(t *testing.T) {
+	t.Parallel()
+	diagnostics := lintSchema(t, `{
+		"type": "object",
+		"properties": {
+			"shape": {"oneOf": [
+				{"type": "integer", "minimum": 0, "maximum": 10},
+				{"type": "integer", "minimum": 0, "maximum": 5},
+				{"type": "string"}
+			]}
+		},
+		"required": ["shape"]
+	}`, nil)
+
+	diagnostic := findDiagnostic(diagnostics, chaff.DiagnosticCodeDeadOneOfBranch, "#/properties/shape/oneOf/1")
+	if diagnostic == nil {
+		t.Fatalf("Expected a dead oneOf branch finding, got %v", diagnostics)
+	}
+
+	if diagnostic.Severity != chaff.DiagnosticSeverityWarning || diagnostic.Line != 6 {
+		t.Errorf("Expected a warning on line 6, got %+v", diagnostic)
+	}
+
+	for _, pointer := range []string{"#/properties/shape/oneOf/0", "#/properties/shape/oneOf/2"} {
+		if findDiagnostic(diagnostics, chaff.DiagnosticCodeDeadOneOfBranch, pointer) != nil {
+			t.Errorf("Expected branch %s not to be reported", pointer)
+		}
+	}
+}
+
+func TestLintUnreachableIfBranch(t *testing.T) {
+	t.Parallel()
+	diagnostics := lintSchema(t, `{
+		"type": "object",
+		"properties": {"kind": {"const": "a"}},
+		"required": ["kind"],
+		"if": {"properties": {"kind": {"const": "a"}}, "required": ["kind"]},
+		"then": {"properties": {"a": {"type": "string"}}},
+		"else": {"properties": {"b": {"type": "string"}}}
+	}`, nil)
+
+	if findDiagnostic(diagnostics, chaff.DiagnosticCodeUnreachableIfBranch, "#/else") == nil {
+		t.Errorf("Expected the else branch to be reported as unreachable, got %v", diagnostics)
+	}
+
+	if findDiagnostic(diagnostics, chaff.DiagnosticCodeUnreachableIfBranch, "#/then") != nil {
+		t.Errorf("Expected the then branch not to be reported")
+	}
+}
+
+func TestLintKeywords(t *testing.T) {
+	t.Parallel()
+	diagnostics := lintSchema(t, `{
+		"type": "object",
+		"x-internal": true,
+		"propertyNames": {"pattern": "^[a-z]+$"},
+		"properties": {
+			"name": {"type": "string", "minLenght": 3, "description": "Name"}
+		}
+	}`, &chaff.LintOptions{Samples: -1})
+
+	unknown := findDiagnostic(diagnostics, chaff.DiagnosticCodeUnknownKeyword, "#/properties/name/minLenght")
+	if unknown == nil || unknown.Severity != chaff.DiagnosticSeverityWarning || unknown.Line != 6 {
+		t.Errorf("Expected an unknown keyword warning on line 6, got %v", diagnostics)
+	}
+
+	unsupported := findDiagnostic(diagnostics, chaff.DiagnosticCodeUnsupportedKeyword, "#/propertyNames")
+	if unsupported == nil || unsupported.Severity != chaff.DiagnosticSeverityUnsupported {
+		t.Errorf("Expected propertyNames to be reported as unsupported, got %v", diagnostics)
+	}
+
+	if len(diagnostics) != 2 {
+		t.Errorf("Expected exactly 2 findings, got %v", diagnostics)
+	}
+}
+
+func TestLintUnsatisfiable(t *testing.T) {
+	t.Parallel()
+	diagnostics := lintSchema(t, `{
+		"type": "object",
+		"properties": {
+			"merged": {"allOf": [{"type": "string"}, {"type": "integer"}]},
+			"enum": {"type": "string", "enum": [1, 2]},
+			"range": {"type": "integer", "minimum": 10, "maximum": 1}
+		}
+	}`, &chaff.LintOptions{Samples: -1})
+
+	expected := map[string]chaff.DiagnosticCode{
+		"#/properties/merged/type": chaff.DiagnosticCodeContradictoryAllOf,
+		"#/properties/enum":        chaff.DiagnosticCodeUnsatisfiable,
+		"#/properties/range":       chaff.DiagnosticCodeUnsatisfiable,
+	}
+
+	for pointer, code := range expected {
+		diagnostic := findDiagnostic(diagnostics, code, pointer)
+		if diagnostic == nil || diagnostic.Severity != chaff.DiagnosticSeverityError {
+			t.Errorf("Expected an %s error at %s, got %v", code, pointer, diagnostics)
+		}
+	}
+
+	if !sort.IsSorted(diagnostics) {
+		t.Errorf("Expected findings to be sorted")
+	}
+}
+
+func TestLintCleanSchema(t *testing.T) {
+	t.Parallel()
+	diagnostics := lintSchema(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "Clean",
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"kind": {"oneOf": [{"const": "a"}, {"const": "b"}]}
+		},
+		"required": ["id", "kind"]
+	}`, nil)
+
+	if len(diagnostics) != 0 {
+		t.Errorf("Expected no findings, got %v", diagnostics)
+	}
+}
+
+func TestLintAtLeast(t *testing.T) {
+	t.Parallel()
+	diagnostics := chaff.Diagnostics{
+		{Severity: chaff.DiagnosticSeverityWarning},
+		{Severity: chaff.DiagnosticSeverityUnsupported},
+		{Severity: chaff.DiagnosticSeverityError},
+	}
+
+	expected := map[chaff.DiagnosticSeverity]int{
+		chaff.DiagnosticSeverityWarning:     3,
+		chaff.DiagnosticSeverityUnsupported: 2,
+		chaff.DiagnosticSeverityError:       1,
+	}
+
+	for severity, count := range expected {
+		if got := len(diagnostics.AtLeast(severity)); got != count {
+			t.Errorf("Expected %d findings at least as severe as %s, got %d", count, severity, got)
+		}
+	}
+}
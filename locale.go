@@ -0,0 +1,219 @@
+package chaff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// Locale specific data used for text, names, addresses, internationalized formats and time offsets
+	localeData struct {
+		// Provider used for text, names and addresses (nil to keep the default data provider)
+		provider *BuiltinDataProvider
+
+		// Words that are valid IDNA labels used for "idn-hostname", "idn-email" and "iri" formats
+		idnLabels []string
+
+		// Top level domain for internationalized host names
+		idnTopLevelDomain string
+
+		// UTC offsets (in minutes) commonly used by the locale
+		utcOffsets []int
+	}
+)
+
+// Locales supported by GeneratorOptions.Locale. Keyed by their primary language subtag
+var locales = map[string]localeData{
+	"en": {
+		utcOffsets: []int{0, -300, -480, 60},
+	},
+	"de": {
+		provider: &BuiltinDataProvider{
+			Words: []string{
+				"haus", "straße", "grün", "über", "schön", "größe", "mädchen", "brücke", "zeitung", "frühling",
+				"müde", "käse", "fußball", "schlüssel", "wald", "buch", "stadt", "wasser", "himmel", "bäcker",
+			},
+			FirstNames:      []string{"Jürgen", "Günther", "Lukas", "Sophie", "Jörg", "Björn", "Käthe", "Mia", "Felix", "Lena"},
+			LastNames:       []string{"Müller", "Schäfer", "Schröder", "Weiß", "Köhler", "Groß", "Fischer", "Krüger", "Hoffmann", "Wagner"},
+			Streets:         []string{"Hauptstraße", "Schulstraße", "Gartenweg", "Bahnhofstraße", "Lindenallee", "Mühlenweg"},
+			Cities:          []string{"München", "Köln", "Düsseldorf", "Nürnberg", "Berlin", "Hamburg", "Lübeck", "Göttingen"},
+			Countries:       []string{"Deutschland", "Österreich", "Schweiz", "Frankreich", "Dänemark", "Belgien"},
+			TopLevelDomains: []string{"de", "at", "ch"},
+		},
+		idnLabels:         []string{"bücher", "straße", "müller", "grün", "schön", "käse", "bäcker", "brücke"},
+		idnTopLevelDomain: "de",
+		utcOffsets:        []int{60, 120},
+	},
+	"ja": {
+		provider: &BuiltinDataProvider{
+			Words: []string{
+				"日本", "東京", "桜", "山", "川", "空", "海", "花", "猫", "犬",
+				"学校", "電車", "会社", "天気", "音楽", "料理", "写真", "時間", "友達", "季節",
+			},
+			FirstNames:         []string{"陽翔", "蓮", "湊", "結菜", "陽葵", "凛", "大和", "さくら", "健太", "美咲"},
+			LastNames:          []string{"佐藤", "鈴木", "高橋", "田中", "伊藤", "渡辺", "山本", "中村", "小林", "加藤"},
+			Streets:            []string{"銀座", "本町", "栄町", "中央通り", "桜通り", "駅前"},
+			Cities:             []string{"東京", "大阪", "京都", "札幌", "福岡", "名古屋", "横浜", "神戸"},
+			Countries:          []string{"日本", "韓国", "中国", "アメリカ", "フランス", "ドイツ"},
+			TopLevelDomains:    []string{"jp"},
+			Unspaced:           true,
+			SentenceTerminator: "。",
+			NameOrderReversed:  true,
+		},
+		idnLabels:         []string{"日本語", "東京", "例え", "桜", "写真", "音楽", "料理", "天気"},
+		idnTopLevelDomain: "jp",
+		utcOffsets:        []int{540},
+	},
+	"ar": {
+		provider: &BuiltinDataProvider{
+			Words: []string{
+				"كتاب", "مدرسة", "بيت", "شمس", "قمر", "بحر", "مدينة", "سماء", "ماء", "وردة",
+				"طريق", "صديق", "عمل", "وقت", "سوق", "جبل", "نهر", "قلم", "باب", "نور",
+			},
+			FirstNames:         []string{"محمد", "أحمد", "علي", "فاطمة", "مريم", "يوسف", "خالد", "نور", "سارة", "عمر"},
+			LastNames:          []string{"الهاشمي", "العلي", "الحسن", "المصري", "الشامي", "القحطاني", "الزهراني", "العمري"},
+			Streets:            []string{"شارع الملك فهد", "شارع النيل", "شارع الجمهورية", "طريق الملك عبدالعزيز", "شارع الحرية"},
+			Cities:             []string{"الرياض", "القاهرة", "دبي", "عمّان", "بيروت", "الدار البيضاء", "جدة", "الدوحة"},
+			Countries:          []string{"السعودية", "مصر", "الإمارات", "الأردن", "لبنان", "المغرب"},
+			TopLevelDomains:    []string{"sa", "eg", "ae"},
+			SentenceTerminator: ".",
+		},
+		idnLabels:         []string{"مثال", "اختبار", "كتاب", "مدرسة", "سوق", "نور", "بيت", "مدينة"},
+		idnTopLevelDomain: "sa",
+		utcOffsets:        []int{120, 180, 240},
+	},
+	"ru": {
+		provider: &BuiltinDataProvider{
+			Words: []string{
+				"дом", "город", "книга", "солнце", "вода", "улица", "время", "работа", "друг", "небо",
+				"лес", "река", "окно", "школа", "море", "дорога", "земля", "слово", "жизнь", "мир",
+			},
+			FirstNames:      []string{"Иван", "Алексей", "Дмитрий", "Ольга", "Анна", "Мария", "Сергей", "Наталья", "Павел", "Елена"},
+			LastNames:       []string{"Иванов", "Смирнов", "Кузнецов", "Попов", "Соколов", "Лебедев", "Козлов", "Новиков"},
+			Streets:         []string{"улица Ленина", "Невский проспект", "улица Пушкина", "Садовая улица", "улица Гагарина"},
+			Cities:          []string{"Москва", "Санкт-Петербург", "Новосибирск", "Казань", "Екатеринбург", "Самара"},
+			Countries:       []string{"Россия", "Казахстан", "Беларусь", "Германия", "Франция", "Китай"},
+			TopLevelDomains: []string{"ru"},
+		},
+		idnLabels:         []string{"пример", "испытание", "книга", "город", "дом", "работа"},
+		idnTopLevelDomain: "рф",
+		utcOffsets:        []int{180, 240, 300, 420},
+	},
+	"zh": {
+		provider: &BuiltinDataProvider{
+			Words: []string{
+				"中国", "北京", "学生", "老师", "朋友", "天气", "工作", "时间", "城市", "音乐",
+				"电脑", "手机", "图书", "山水", "花园", "家庭", "公司", "文化", "世界", "生活",
+			},
+			FirstNames:         []string{"伟", "芳", "娜", "敏", "静", "强", "磊", "洋", "艳", "杰"},
+			LastNames:          []string{"王", "李", "张", "刘", "陈", "杨", "黄", "赵", "周", "吴"},
+			Streets:            []string{"人民路", "中山路", "解放路", "建设路", "长安街", "和平路"},
+			Cities:             []string{"北京", "上海", "广州", "深圳", "成都", "杭州", "武汉", "西安"},
+			Countries:          []string{"中国", "日本", "韩国", "美国", "法国", "德国"},
+			TopLevelDomains:    []string{"cn"},
+			Unspaced:           true,
+			SentenceTerminator: "。",
+			NameOrderReversed:  true,
+		},
+		idnLabels:         []string{"例子", "中文", "北京", "音乐", "图书", "文化"},
+		idnTopLevelDomain: "cn",
+		utcOffsets:        []int{480},
+	},
+}
+
+// Returns the names of all locales that can be passed to GeneratorOptions.Locale
+func SupportedLocales() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Returns whether the given locale is one of the SupportedLocales, optionally followed by a
+// script and / or region (e.g. "de-DE", "ja_JP" or "zh-Hans-CN"). Unsupported locales fall back to English
+func IsSupportedLocale(locale string) bool {
+	_, ok := getLocale(locale)
+	return ok
+}
+
+// Looks up the data for a locale such as "de", "de-DE" or "ja_JP". Returns false for unknown, malformed or empty locales
+func getLocale(locale string) (localeData, bool) {
+	subtags := strings.Split(strings.ReplaceAll(locale, "_", "-"), "-")
+	for _, subtag := range subtags[1:] {
+		if !isLocaleSubtag(subtag) {
+			return localeData{}, false
+		}
+	}
+
+	data, ok := locales[strings.ToLower(subtags[0])]
+	return data, ok
+}
+
+// Script (e.g. "Hans") and region (e.g. "DE" or "419") subtags that can follow the language of a locale
+func isLocaleSubtag(subtag string) bool {
+	isAll := func(check func(rune) bool) bool {
+		return strings.IndexFunc(subtag, func(r rune) bool { return !check(r) }) == -1
+	}
+
+	isLetter := func(r rune) bool { return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') }
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+	switch len(subtag) {
+	case 2, 4:
+		return isAll(isLetter)
+	case 3:
+		return isAll(isDigit)
+	default:
+		return false
+	}
+}
+
+// Generates an internationalized host name (e.g "bücher.de") falling back to an ascii domain for locales without IDN data
+func generateIdnHostname(opts *GeneratorOptions) string {
+	locale, ok := getLocale(opts.Locale)
+	if !ok || len(locale.idnLabels) == 0 {
+		return opts.DataProvider.DomainName(opts.Rand)
+	}
+
+	return fmt.Sprintf("%s.%s", opts.Rand.StringChoice(&locale.idnLabels), locale.idnTopLevelDomain)
+}
+
+// Generates an internationalized email address with a unicode local part (RFC 6531)
+func generateIdnEmail(opts *GeneratorOptions) string {
+	locale, ok := getLocale(opts.Locale)
+	if !ok || len(locale.idnLabels) == 0 {
+		return opts.DataProvider.Email(opts.Rand)
+	}
+
+	localPart := strings.ToLower(opts.DataProvider.FirstName(opts.Rand))
+	if strings.ContainsAny(localPart, " \t\"@") {
+		localPart = opts.Rand.StringChoice(&locale.idnLabels)
+	}
+
+	return fmt.Sprintf("%s@%s", localPart, generateIdnHostname(opts))
+}
+
+// Generates an IRI using an internationalized host name and path
+func generateIri(opts *GeneratorOptions) string {
+	locale, ok := getLocale(opts.Locale)
+	if !ok || len(locale.idnLabels) == 0 {
+		return opts.DataProvider.URL(opts.Rand)
+	}
+
+	return fmt.Sprintf("https://%s/%s", generateIdnHostname(opts), opts.Rand.StringChoice(&locale.idnLabels))
+}
+
+// Picks one of the UTC offsets used by the locale. Times are kept in UTC if no locale is set
+func getLocaleLocation(opts *GeneratorOptions) *time.Location {
+	locale, ok := getLocale(opts.Locale)
+	if !ok || len(locale.utcOffsets) == 0 {
+		return time.UTC
+	}
+
+	offset := locale.utcOffsets[opts.Rand.RandomInt(0, len(locale.utcOffsets))]
+	return time.FixedZone("", offset*60)
+}
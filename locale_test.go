@@ -0,0 +1,111 @@
+package chaff_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/ryanolee/go-chaff"
+	test "github.com/ryanolee/go-chaff/internal/test_utils"
+)
+
+func TestLocale(t *testing.T) {
+	t.Parallel()
+	for _, locale := range chaff.SupportedLocales() {
+		t.Run(locale, func(t *testing.T) {
+			test.TestJsonSchemaDirWithConfig(t, "test_data/locale", 50, nil, func() *chaff.GeneratorOptions {
+				return &chaff.GeneratorOptions{
+					Locale: locale,
+				}
+			})
+		})
+	}
+}
+
+func TestLocaleScripts(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaFileWithDefaults("test_data/locale/locale_strings.json")
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	cases := []struct {
+		locale string
+		script *unicode.RangeTable
+		offset string
+	}{
+		{locale: "ja-JP", script: unicode.Han, offset: "+09:00"},
+		{locale: "ar", script: unicode.Arabic},
+		{locale: "ru_RU", script: unicode.Cyrillic},
+		{locale: "zh", script: unicode.Han, offset: "+08:00"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.locale, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				value := generator.Generate(&chaff.GeneratorOptions{Locale: tc.locale}).(map[string]interface{})
+				for _, key := range []string{"testLorem", "testCity", "testIdnHostname", "testIdnEmail", "testIri"} {
+					if !containsScript(value[key].(string), tc.script) {
+						t.Fatalf("Expected %s to contain characters from the locale's script, got %q", key, value[key])
+					}
+				}
+
+				if strings.Count(value["testIdnEmail"].(string), "@") != 1 {
+					t.Fatalf("Expected a single @ in idn-email, got %q", value["testIdnEmail"])
+				}
+
+				dateTime, err := time.Parse(time.RFC3339, value["testDateTime"].(string))
+				if err != nil {
+					t.Fatalf("Failed to parse date-time %q: %s", value["testDateTime"], err)
+				}
+
+				if tc.offset != "" && !strings.HasSuffix(value["testDateTime"].(string), tc.offset) {
+					t.Fatalf("Expected date-time in the locale's offset (%s), got %s", tc.offset, dateTime)
+				}
+			}
+		})
+	}
+}
+
+func TestLocaleUnknownFallsBack(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{"type": "string", "format": "date-time"}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	value := generator.Generate(&chaff.GeneratorOptions{Locale: "xx"}).(string)
+	if !strings.HasSuffix(value, "Z") {
+		t.Fatalf("Expected UTC date-time for an unknown locale, got %s", value)
+	}
+}
+
+func TestIsSupportedLocale(t *testing.T) {
+	t.Parallel()
+	for locale, expected := range map[string]bool{
+		"de":         true,
+		"de_CH":      true,
+		"ja-JP":      true,
+		"zh-Hans-CN": true,
+		"en-419":     true,
+		"":           false,
+		"fr":         false,
+		"de_CHX":     false,
+		"en-":        false,
+	} {
+		if chaff.IsSupportedLocale(locale) != expected {
+			t.Errorf("Expected IsSupportedLocale(%q) to be %t", locale, expected)
+		}
+	}
+}
+
+func containsScript(value string, script *unicode.RangeTable) bool {
+	for _, r := range value {
+		if unicode.Is(script, r) {
+			return true
+		}
+	}
+
+	return false
+}
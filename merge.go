@@ -44,6 +44,7 @@ func mergeSchemaNodes(metadata *parserMetadata, nodes ...schemaNode) (schemaNode
 
 		// Merge object properties
 		mergedNode.Properties = mergeProperties(metadata, mergedNode.Properties, node.Properties)
+		mergedNode.propertyOrder = funk.UniqString(append(append([]string{}, mergedNode.propertyOrder...), node.propertyOrder...))
 		mergedNode.AdditionalProperties = mergeNodeOrFalse(metadata, mergedNode.AdditionalProperties, node.AdditionalProperties, "additionalProperties")
 		mergedNode.PatternProperties = mergePatternProperties(metadata, mergedNode.PatternProperties, node.PatternProperties)
 
@@ -66,6 +67,9 @@ func mergeSchemaNodes(metadata *parserMetadata, nodes ...schemaNode) (schemaNode
 		// Merge simple properties
 		mergedNode = mergeSchemaNodeSimpleProperties(metadata, mergedNode, node)
 
+		// Merge generation hints
+		mergedNode.XChaff = mergeHints(mergedNode.XChaff, node.XChaff)
+
 		// Propagate not-constraints from prior processing (e.g., notMerge sets
 		// .constraints on sub-nodes). Accumulate them so that sequential merges
 		// don't lose earlier exclusions.
@@ -350,7 +354,7 @@ func mergeSchemaNodeSimpleProperties(metadata *parserMetadata, baseNode schemaNo
 	baseNode.Maximum = util.MinFloatPtr(otherNode.Maximum, baseNode.Maximum)
 	baseNode.ExclusiveMinimum = util.MaxFloatPtr(otherNode.ExclusiveMinimum, baseNode.ExclusiveMinimum)
 	baseNode.ExclusiveMaximum = util.MinFloatPtr(otherNode.ExclusiveMaximum, baseNode.ExclusiveMaximum)
-	baseNode.MultipleOf = util.FindHcf(otherNode.MultipleOf, baseNode.MultipleOf)
+	baseNode.MultipleOf = util.FindLcm(otherNode.MultipleOf, baseNode.MultipleOf)
 
 	// Merge simple string properties
 	warnIfBothSetAndAreDifferent(metadata, "pattern", baseNode.Pattern, otherNode.Pattern)
@@ -359,6 +363,16 @@ func mergeSchemaNodeSimpleProperties(metadata *parserMetadata, baseNode schemaNo
 	warnIfBothSetAndAreDifferent(metadata, "format", baseNode.Format, otherNode.Format)
 	baseNode.Format = util.GetPtr(otherNode.Format, baseNode.Format)
 
+	warnIfBothSetAndAreDifferent(metadata, "formatMinimum", baseNode.FormatMinimum, otherNode.FormatMinimum)
+	baseNode.FormatMinimum = util.GetPtr(otherNode.FormatMinimum, baseNode.FormatMinimum)
+
+	warnIfBothSetAndAreDifferent(metadata, "formatMaximum", baseNode.FormatMaximum, otherNode.FormatMaximum)
+	baseNode.FormatMaximum = util.GetPtr(otherNode.FormatMaximum, baseNode.FormatMaximum)
+
+	// Annotations
+	baseNode.Title = util.GetPtr(otherNode.Title, baseNode.Title)
+	baseNode.Description = util.GetPtr(otherNode.Description, baseNode.Description)
+
 	// Simple slice properties
 	baseNode.Required = util.MergeSlicePtrs(baseNode.Required, otherNode.Required)
 
@@ -376,7 +390,7 @@ func warnIfBothSetAndAreDifferent[T comparable](metadata *parserMetadata, field
 }
 
 func warnConfigMergeError(metadata *parserMetadata, field string, err error) {
-	metadata.Errors.AddErrorWithSubpath("/config_merge_error", fmt.Errorf("error merging field %s: %w", field, err))
+	metadata.Errors.AddErrorWithSubpath("/config_merge_error", newDiagnosticError(DiagnosticSeverityWarning, DiagnosticCodeMergeConflict, fmt.Errorf("error merging field %s: %w", field, err)))
 }
 
 func mergeResolveReference(metadata *parserMetadata, node schemaNode) (schemaNode, error) {
@@ -0,0 +1,175 @@
+package chaff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	jsonschemaV6 "github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+type (
+	// Returned when a schema document is not valid against the meta-schema of its dialect
+	// (See ParserOptions.ValidateSchema)
+	SchemaValidationError struct {
+		// URI of the document that failed validation
+		Document string
+
+		// A diagnostic for every location in the document that failed validation
+		Diagnostics Diagnostics
+	}
+)
+
+// Meta-schema used for documents without a "$schema" or with an unknown one
+const defaultMetaSchemaUrl = "https://json-schema.org/draft/2020-12/schema"
+
+// Meta-schemas that can be validated against. These are embedded in the validator so no network is needed
+var supportedMetaSchemaUrls = []string{
+	"https://json-schema.org/draft/2020-12/schema",
+	"https://json-schema.org/draft/2019-09/schema",
+	"http://json-schema.org/draft-07/schema",
+	"http://json-schema.org/draft-06/schema",
+	"http://json-schema.org/draft-04/schema",
+}
+
+var (
+	metaSchemas     = map[string]*jsonschemaV6.Schema{}
+	metaSchemasLock sync.Mutex
+)
+
+func (e *SchemaValidationError) Error() string {
+	messages := make([]string, 0, len(e.Diagnostics))
+	for _, diagnostic := range e.Diagnostics {
+		messages = append(messages, fmt.Sprintf("%s: %s", diagnostic.Pointer, diagnostic.Message))
+	}
+
+	return fmt.Sprintf("schema '%s' is not valid against its meta-schema: %s", e.Document, strings.Join(messages, ", "))
+}
+
+// Validates the source of a schema document against the meta-schema of the dialect given by its "$schema".
+// Returns a *SchemaValidationError if it is invalid. Sources that can not be decoded are left for the parser to report
+func validateSchemaSource(document string, source []byte) error {
+	data, err := decodeSchemaSource(document, source)
+	if err != nil {
+		return nil
+	}
+
+	instance, err := jsonschemaV6.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+
+	metaSchema, err := getMetaSchema(getMetaSchemaUrl(instance))
+	if err != nil {
+		return err
+	}
+
+	err = metaSchema.Validate(instance)
+	var validationErr *jsonschemaV6.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	// Alternatives of the same location (e.g. of "anyOf") are reported as one diagnostic
+	messages := map[string][]string{}
+	pointers := []string{}
+	collectMetaSchemaViolations(validationErr, func(pointer string, message string) {
+		if _, ok := messages[pointer]; !ok {
+			pointers = append(pointers, pointer)
+		}
+
+		for _, existing := range messages[pointer] {
+			if existing == message {
+				return
+			}
+		}
+
+		messages[pointer] = append(messages[pointer], message)
+	})
+
+	diagnostics := Diagnostics{}
+	for _, pointer := range pointers {
+		diagnostic := Diagnostic{
+			Severity: DiagnosticSeverityError,
+			Code:     DiagnosticCodeMetaSchemaViolation,
+			Message:  strings.Join(messages[pointer], " or "),
+			Document: document,
+			Pointer:  pointer,
+		}
+
+		diagnostic.Line, diagnostic.Column = locateJsonPointer(source, pointer)
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	sort.Sort(diagnostics)
+	return &SchemaValidationError{
+		Document:    document,
+		Diagnostics: diagnostics,
+	}
+}
+
+// Calls report for each of the innermost causes of the validation error
+func collectMetaSchemaViolations(err *jsonschemaV6.ValidationError, report func(pointer string, message string)) {
+	if len(err.Causes) == 0 {
+		output := err.BasicOutput()
+		if output.Error != nil {
+			report("#"+formatJsonPointer(err.InstanceLocation), output.Error.String())
+		}
+
+		return
+	}
+
+	for _, cause := range err.Causes {
+		collectMetaSchemaViolations(cause, report)
+	}
+}
+
+// Returns the meta-schema URL for the dialect of the given document
+func getMetaSchemaUrl(instance interface{}) string {
+	object, ok := instance.(map[string]interface{})
+	if !ok {
+		return defaultMetaSchemaUrl
+	}
+
+	dialect, ok := object["$schema"].(string)
+	if !ok {
+		return defaultMetaSchemaUrl
+	}
+
+	// Dialects are matched regardless of the scheme and trailing empty fragment they are given with
+	dialect = strings.TrimSuffix(trimUrlScheme(dialect), "#")
+	for _, url := range supportedMetaSchemaUrls {
+		if trimUrlScheme(url) == dialect {
+			return url
+		}
+	}
+
+	return defaultMetaSchemaUrl
+}
+
+func trimUrlScheme(url string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+}
+
+// Compiles (and caches) the meta-schema at the given URL
+func getMetaSchema(url string) (*jsonschemaV6.Schema, error) {
+	metaSchemasLock.Lock()
+	defer metaSchemasLock.Unlock()
+
+	if metaSchema, ok := metaSchemas[url]; ok {
+		return metaSchema, nil
+	}
+
+	compiler := jsonschemaV6.NewCompiler()
+	compiler.UseLoader(internalOnlyLoader{})
+	metaSchema, err := compiler.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile meta-schema '%s': %w", url, err)
+	}
+
+	metaSchemas[url] = metaSchema
+	return metaSchema, nil
+}
@@ -0,0 +1,118 @@
+package chaff_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+func TestValidateSchema(t *testing.T) {
+	t.Parallel()
+	_, err := chaff.ParseSchemaString(`{
+		"type": "object",
+		"required": true,
+		"properties": {
+			"kind": {"type": "strng"},
+			"age": {"type": "integer", "minimum": "5"}
+		}
+	}`, &chaff.ParserOptions{ValidateSchema: true})
+
+	var validationErr *chaff.SchemaValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a schema validation error, got %v", err)
+	}
+
+	expected := map[string]int{
+		"#/required":               3,
+		"#/properties/kind/type":   5,
+		"#/properties/age/minimum": 6,
+	}
+
+	if len(validationErr.Diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %v", len(expected), validationErr.Diagnostics)
+	}
+
+	for _, diagnostic := range validationErr.Diagnostics {
+		line, ok := expected[diagnostic.Pointer]
+		if !ok || diagnostic.Line != line {
+			t.Errorf("Unexpected diagnostic %s", diagnostic)
+		}
+
+		if diagnostic.Code != chaff.DiagnosticCodeMetaSchemaViolation || diagnostic.Severity != chaff.DiagnosticSeverityError {
+			t.Errorf("Expected a meta-schema violation error, got %s", diagnostic)
+		}
+	}
+
+	if !strings.Contains(err.Error(), "#/properties/age/minimum: got string, want number") {
+		t.Errorf("Expected a readable error, got %s", err)
+	}
+}
+
+func TestValidateSchemaDialect(t *testing.T) {
+	t.Parallel()
+
+	// "exclusiveMinimum" is a boolean in draft-04 and a number from draft-06 onwards
+	_, err := chaff.ParseSchemaString(`{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"type": "integer",
+		"exclusiveMinimum": 5
+	}`, &chaff.ParserOptions{ValidateSchema: true})
+
+	var validationErr *chaff.SchemaValidationError
+	if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), "#/exclusiveMinimum: got number, want boolean") {
+		t.Errorf("Expected the draft-04 meta-schema to be used, got %v", err)
+	}
+
+	_, err = chaff.ParseSchemaString(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "integer",
+		"exclusiveMinimum": 5
+	}`, &chaff.ParserOptions{ValidateSchema: true})
+
+	if err != nil {
+		t.Errorf("Expected the draft-07 schema to be valid, got %s", err)
+	}
+}
+
+func TestValidateSchemaDisabled(t *testing.T) {
+	t.Parallel()
+	_, err := chaff.ParseSchemaStringWithDefaults(`{"type": "integer", "minimum": "5"}`)
+
+	var validationErr *chaff.SchemaValidationError
+	if err == nil || errors.As(err, &validationErr) {
+		t.Errorf("Expected a plain parse error without validation, got %v", err)
+	}
+}
+
+func TestValidateSchemaExternalDocument(t *testing.T) {
+	t.Parallel()
+	opts := getDocumentDiagnosticsConfig()
+	opts.ValidateSchema = true
+	generator, err := chaff.ParseSchemaString(`{
+		"type": "object",
+		"properties": {
+			"person": {"$ref": "test_data/diagnostics/invalid.json"}
+		}
+	}`, opts)
+
+	if err != nil {
+		t.Fatalf("Expected the root schema to parse, got %s", err)
+	}
+
+	var found *chaff.Diagnostic
+	for _, diagnostic := range generator.Diagnostics() {
+		if diagnostic.Code == chaff.DiagnosticCodeMetaSchemaViolation {
+			found = &diagnostic
+		}
+	}
+
+	if found == nil {
+		t.Fatalf("Expected a meta-schema violation for the external document, got %v", generator.Diagnostics())
+	}
+
+	if !strings.HasSuffix(found.Document, "test_data/diagnostics/invalid.json") || found.Pointer != "#/properties/age/minimum" || found.Line != 4 {
+		t.Errorf("Expected the violation to point into the external document, got %s", found)
+	}
+}
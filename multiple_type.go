@@ -2,6 +2,7 @@ package chaff
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/thoas/go-funk"
@@ -47,3 +48,10 @@ func (g multipleTypeGenerator) String() string {
 
 	return fmt.Sprintf("MultiTypeGenerator{%s}", strings.Join(formattedGenerators, ","))
 }
+
+func (g multipleTypeGenerator) describe(node *GeneratorNode) {
+	node.Kind = GeneratorKindMultipleType
+	for i, generator := range g.generators {
+		node.addChild("type", strconv.Itoa(i), generator)
+	}
+}
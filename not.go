@@ -199,6 +199,7 @@ func notMerge(metadata *parserMetadata, node schemaNode, notNode schemaNode) (sc
 	newNode.Then = node.Then
 	newNode.Else = node.Else
 	newNode.constraints = node.constraints
+	newNode.XChaff = node.XChaff
 
 	return newNode, &constraints
 
@@ -700,7 +701,7 @@ func notMergeItemData(metadata *parserMetadata, nodePtr *itemsData, notNodePtr *
 	}
 
 	if node.Nodes != nil || notNode.Nodes != nil {
-		warnField(metadata, "not/items [As Array]", fmt.Errorf("not merging 'items' as an array is not yet supported. Please use the more recent 'prefixItems' functionality instead if possible"))
+		warnUnsupported(metadata, "not/items [As Array]", fmt.Errorf("not merging 'items' as an array is not yet supported. Please use the more recent 'prefixItems' functionality instead if possible"))
 	}
 
 	if node.Node == nil && notNode.Node == nil {
@@ -1112,13 +1113,18 @@ func resolveBool(fieldName string, value *bool, notValue *bool) *bool {
 // Warnings
 func warnUnsupportedField(metadata *parserMetadata, fieldName string, fieldEmptyFunc func() bool) {
 	if fieldEmptyFunc() {
-		warnField(metadata, fieldName, fmt.Errorf("not for '%s' not yet supported", fieldName))
+		warnUnsupported(metadata, fieldName, fmt.Errorf("not for '%s' not yet supported", fieldName))
 	}
 }
 
+func warnUnsupported(metadata *parserMetadata, fieldName string, err error) {
+	errorPath := fmt.Sprintf("/%s", fieldName)
+	metadata.Errors.AddErrorWithSubpath(errorPath, newDiagnosticError(DiagnosticSeverityUnsupported, DiagnosticCodeUnsupportedKeyword, err))
+}
+
 func warnField(metadata *parserMetadata, fieldName string, err error) {
 	if err != nil {
 		errorPath := fmt.Sprintf("/%s", fieldName)
-		metadata.Errors.AddErrorWithSubpath(errorPath, err)
+		metadata.Errors.AddErrorWithSubpath(errorPath, newDiagnosticError(DiagnosticSeverityWarning, DiagnosticCodeKeywordIgnored, err))
 	}
 }
@@ -2,6 +2,9 @@ package chaff
 
 type (
 	nullGenerator struct {
+		// Set when the schema asks for null rather than the generator being used
+		// in place of a schema that could not be compiled
+		explicit bool
 	}
 )
 
@@ -12,7 +15,7 @@ type (
 // }
 
 func parseNull(node schemaNode) (nullGenerator, error) {
-	return nullGenerator{}, nil
+	return nullGenerator{explicit: true}, nil
 }
 
 func (g nullGenerator) Generate(opts *GeneratorOptions) interface{} {
@@ -23,3 +26,8 @@ func (g nullGenerator) Generate(opts *GeneratorOptions) interface{} {
 func (g nullGenerator) String() string {
 	return "NullGenerator"
 }
+
+func (g nullGenerator) describe(node *GeneratorNode) {
+	node.Kind = GeneratorKindNull
+	node.Fallback = !g.explicit
+}
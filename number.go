@@ -1,9 +1,11 @@
 package chaff
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
 
 	"github.com/ryanolee/go-chaff/internal/util"
 	"github.com/ryanolee/go-chaff/rand"
@@ -11,7 +13,10 @@ import (
 
 type (
 	numberGeneratorType string
-	numberGenerator     struct {
+
+	// Generates float64s for "number" schemas and ints for "integer" schemas. Integers with either bound beyond
+	// 2^53 are all generated as json.Numbers (even those that would fit a float64) so they keep all of their digits
+	numberGenerator struct {
 		Type       numberGeneratorType
 		Min        float64
 		Max        float64
@@ -33,6 +38,9 @@ const (
 	generatorTypeNumber  numberGeneratorType = "number"
 
 	defaultOffset = 10
+
+	// Integers with a larger magnitude can not all be represented exactly as float64s
+	maxExactInteger = 1 << 53
 )
 
 // Parses the "type" keyword of a schema when it is a "number" or "integer"
@@ -112,7 +120,7 @@ func resolveMinMaxForNode(node schemaNode, mustBeAnInteger bool) (float64, float
 	if mustBeAnInteger {
 		// If min and max are both non-integer values and round to the same integer
 		if math.Floor(min) == math.Floor(max) {
-			return 0, 0, fmt.Errorf("minimum and maximum do not allow for any integers (min: %f, max: %f)", min, max)
+			return 0, 0, newDiagnosticError(DiagnosticSeverityError, DiagnosticCodeUnsatisfiable, fmt.Errorf("minimum and maximum do not allow for any integers (min: %f, max: %f)", min, max))
 		}
 
 		min = math.Ceil(min)
@@ -121,7 +129,7 @@ func resolveMinMaxForNode(node schemaNode, mustBeAnInteger bool) (float64, float
 
 	// Validate min and max
 	if min > max {
-		return 0, 0, fmt.Errorf("minimum cannot be greater than maximum (min: %f, max: %f)", min, max)
+		return 0, 0, newDiagnosticError(DiagnosticSeverityError, DiagnosticCodeUnsatisfiable, fmt.Errorf("minimum cannot be greater than maximum (min: %f, max: %f)", min, max))
 	}
 
 	// Validate multipleOf
@@ -138,7 +146,7 @@ func resolveMinMaxForNode(node schemaNode, mustBeAnInteger bool) (float64, float
 		multiplesInRange := countMultiplesInRange(min, max, multipleOf)
 
 		if multiplesInRange == 0 {
-			return 0, 0, errors.New("minimum and maximum do not allow for any multiples of multipleOf")
+			return 0, 0, newDiagnosticError(DiagnosticSeverityError, DiagnosticCodeUnsatisfiable, errors.New("minimum and maximum do not allow for any multiples of multipleOf"))
 		}
 	}
 	return min, max, nil
@@ -175,6 +183,10 @@ func roundToInfinitesimal(f float64) float64 {
 
 func (g *numberGenerator) Generate(opts *GeneratorOptions) interface{} {
 	opts.overallComplexity++
+	if g.Type == generatorTypeInteger && (math.Abs(g.Min) > maxExactInteger || math.Abs(g.Max) > maxExactInteger) {
+		return g.generateLargeInteger(opts)
+	}
+
 	result := 0.0
 	if g.Type == generatorTypeInteger && g.MultipleOf != 0 {
 		result = float64(generateMultipleOf(*opts.Rand, g.Min, g.Max, g.MultipleOf))
@@ -209,6 +221,53 @@ func (g *numberGenerator) Generate(opts *GeneratorOptions) interface{} {
 	return result
 }
 
+// Generates integers with bounds beyond 2^53 using exact arithmetic as float64s would skip most of the
+// integers in range. The bounds themselves are still only as precise as the float64s they were parsed into.
+// Every integer is returned as a json.Number so the type does not depend on the value drawn
+func (g *numberGenerator) generateLargeInteger(opts *GeneratorOptions) interface{} {
+	min, _ := new(big.Float).SetFloat64(g.Min).Int(nil)
+	max, _ := new(big.Float).SetFloat64(g.Max).Int(nil)
+	step := big.NewInt(1)
+	if g.MultipleOf != 0 {
+		step, _ = new(big.Float).SetFloat64(g.MultipleOf).Int(nil)
+
+		// Snap the bounds to the first and last multiples in range
+		first, remainder := new(big.Int).DivMod(min, step, new(big.Int))
+		if remainder.Sign() != 0 {
+			first.Add(first, big.NewInt(1))
+		}
+
+		min = first.Mul(first, step)
+		max = new(big.Int).Sub(max, new(big.Int).Mod(max, step))
+	}
+
+	// Pick one of the (max - min) / step + 1 values in range
+	count := new(big.Int).Sub(max, min)
+	count.Div(count, step).Add(count, big.NewInt(1))
+	if count.Sign() <= 0 {
+		return json.Number(min.String())
+	}
+
+	offset := new(big.Int).Rand(opts.Rand.Rand, count)
+	result := offset.Mul(offset, step).Add(offset, min)
+	return json.Number(result.String())
+}
+
 func (g *numberGenerator) String() string {
 	return "NumberGenerator"
 }
+
+func (g *numberGenerator) describe(node *GeneratorNode) {
+	node.Kind = GeneratorKindNumber
+	if g.Type == generatorTypeInteger {
+		node.Kind = GeneratorKindInteger
+	}
+
+	min, max := g.Min, g.Max
+	node.Bounds.Minimum = &min
+	node.Bounds.Maximum = &max
+	if g.MultipleOf != 0 {
+		multipleOf := g.MultipleOf
+		node.Bounds.MultipleOf = &multipleOf
+	}
+}
@@ -1,8 +1,10 @@
 package chaff
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"math/big"
 	"testing"
 
 	"github.com/ryanolee/go-chaff/rand"
@@ -109,6 +111,45 @@ func TestNumberGenerate(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Test large int generation", func(t *testing.T) {
+		testCases := [][]float64{
+			{1 << 60, 1<<60 + 4096, 0},
+			{-(1 << 62), -(1<<62 - 2048), 0},
+			{1 << 60, 1 << 61, 1 << 10},
+			{1e20, 1e21, 7},
+			{0, 1 << 54, 1 << 52},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(fmt.Sprintf("TestCase Min: %f max: %f multipleOf: %f", testCase[0], testCase[1], testCase[2]), func(t *testing.T) {
+				generator := &numberGenerator{
+					Min:        testCase[0],
+					Max:        testCase[1],
+					MultipleOf: testCase[2],
+					Type:       generatorTypeInteger,
+				}
+
+				for i := 0; i < 100; i++ {
+					result := generator.Generate(&GeneratorOptions{
+						Rand: rand.NewRandUtilFromTime(),
+					})
+
+					assert.IsType(t, json.Number(""), result)
+					integer, ok := new(big.Int).SetString(string(result.(json.Number)), 10)
+					assert.True(t, ok, "Expected an integer, got %s", result)
+
+					min, _ := big.NewFloat(testCase[0]).Int(nil)
+					max, _ := big.NewFloat(testCase[1]).Int(nil)
+					assert.True(t, integer.Cmp(min) >= 0 && integer.Cmp(max) <= 0, "Expected %s to be within bounds", integer)
+
+					if testCase[2] != 0 {
+						assert.Zero(t, new(big.Int).Mod(integer, big.NewInt(int64(testCase[2]))).Sign())
+					}
+				}
+			})
+		}
+	})
 }
 
 func TestNumberParse(t *testing.T) {
@@ -2,6 +2,7 @@ package chaff
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/ryanolee/go-chaff/internal/regen"
 	"github.com/ryanolee/go-chaff/internal/util"
@@ -24,6 +25,12 @@ type (
 		MinProperties int
 		MaxProperties int
 		Required      []string
+
+		// Inclusion probabilities for optional properties given through "x-chaff" hints
+		PropertyProbabilities map[string]float64
+
+		// Declared properties in the order they appear in the schema
+		PropertyOrder []string
 	}
 )
 
@@ -55,19 +62,19 @@ func parseObject(node schemaNode, metadata *parserMetadata) (Generator, error) {
 	}
 
 	if node.MaxProperties != nil && minProperties > maxProperties {
-		return nullGenerator{}, fmt.Errorf("minProperties (%d) must be less than or equal to MaxProperties (%d)", minProperties, maxProperties)
+		return nullGenerator{}, newDiagnosticError(DiagnosticSeverityError, DiagnosticCodeUnsatisfiable, fmt.Errorf("minProperties (%d) must be less than or equal to MaxProperties (%d)", minProperties, maxProperties))
 	}
 
 	// Validate Required Properties
 	if node.MaxProperties != nil && len(requiredProperties) > maxProperties {
-		return nullGenerator{}, fmt.Errorf("required properties must have a length of less than or equal to MaxProperties (Max Properties: %d, Length of required %d)", node.MaxProperties, len(requiredProperties))
+		return nullGenerator{}, newDiagnosticError(DiagnosticSeverityError, DiagnosticCodeUnsatisfiable, fmt.Errorf("required properties must have a length of less than or equal to MaxProperties (Max Properties: %d, Length of required %d)", node.MaxProperties, len(requiredProperties)))
 	}
 
 	// Validate additionalProperties
 	additionalProperties := util.GetZeroIfNil(node.AdditionalProperties, schemaNodeOrFalse{})
 	if additionalProperties.IsFalse && node.PatternProperties == nil && minProperties > len(properties) {
-		return nullGenerator{}, fmt.Errorf("given additional properties are not allowed and there are no pattern properties the minProperties must be less than or equal to the number of"+
-			"available properties. (minProperties: %d, propertiesDefined: %d)", node.MinProperties, len(properties))
+		return nullGenerator{}, newDiagnosticError(DiagnosticSeverityError, DiagnosticCodeUnsatisfiable, fmt.Errorf("given additional properties are not allowed and there are no pattern properties the minProperties must be less than or equal to the number of"+
+			"available properties. (minProperties: %d, propertiesDefined: %d)", node.MinProperties, len(properties)))
 	}
 
 	patternProperties, patternPropertiesRegex := parsePatternProperties(node, metadata)
@@ -78,6 +85,8 @@ func parseObject(node schemaNode, metadata *parserMetadata) (Generator, error) {
 		MaxProperties: maxProperties,
 
 		Properties:             parseProperties(node, metadata),
+		PropertyProbabilities:  parsePropertyProbabilities(node),
+		PropertyOrder:          getPropertyOrder(properties, node.propertyOrder),
 		PatternProperties:      patternProperties,
 		PatternPropertiesRegex: patternPropertiesRegex,
 
@@ -98,7 +107,9 @@ func parseProperties(node schemaNode, metadata *parserMetadata) map[string]Gener
 	ref := metadata.ReferenceHandler
 	for name, prop := range *node.Properties {
 		refPath := fmt.Sprintf("/properties/%s", name)
-		propGenerator, err := ref.ParseNodeInScope(refPath, prop, metadata)
+		propGenerator, err := parseNodeWithPropertyName(name, metadata, func() (Generator, error) {
+			return ref.ParseNodeInScope(refPath, prop, metadata)
+		})
 		if err != nil {
 			propGenerator = nullGenerator{}
 		}
@@ -109,13 +120,39 @@ func parseProperties(node schemaNode, metadata *parserMetadata) map[string]Gener
 	return properties
 }
 
+// Collects the "x-chaff" inclusion probabilities of any properties that define one.
+// Invalid probabilities are reported when the property itself is parsed.
+func parsePropertyProbabilities(node schemaNode) map[string]float64 {
+	probabilities := make(map[string]float64)
+	if node.Properties == nil {
+		return probabilities
+	}
+
+	for name, prop := range *node.Properties {
+		hints := prop.XChaff
+		if hints == nil || hints.err != nil || hints.Probability == nil {
+			continue
+		}
+
+		if *hints.Probability < 0 || *hints.Probability > 1 {
+			continue
+		}
+
+		probabilities[name] = *hints.Probability
+	}
+
+	return probabilities
+}
+
 func parseAdditionalProperties(node schemaNode, metadata *parserMetadata) Generator {
 	if node.AdditionalProperties == nil || node.AdditionalProperties.IsFalse || node.AdditionalProperties.Schema == nil {
 		return nil
 	}
 	ref := metadata.ReferenceHandler
 	refPath := "/additionalProperties"
-	additionalProperties, err := ref.ParseNodeInScope(refPath, *node.AdditionalProperties.Schema, metadata)
+	additionalProperties, err := parseNodeWithPropertyName("", metadata, func() (Generator, error) {
+		return ref.ParseNodeInScope(refPath, *node.AdditionalProperties.Schema, metadata)
+	})
 
 	if err != nil {
 		return nullGenerator{}
@@ -137,7 +174,9 @@ func parsePatternProperties(node schemaNode, metadata *parserMetadata) (map[stri
 		refPath := fmt.Sprintf("/patternProperties/%s", regex)
 
 		// Parse the schema node
-		propGenerator, err := ref.ParseNodeInScope(refPath, property, metadata)
+		propGenerator, err := parseNodeWithPropertyName("", metadata, func() (Generator, error) {
+			return ref.ParseNodeInScope(refPath, property, metadata)
+		})
 		if err != nil {
 			propGenerator = nullGenerator{}
 		}
@@ -164,23 +203,48 @@ func (g objectGenerator) Generate(opts *GeneratorOptions) interface{} {
 		return nil
 	}
 
-	// Generate Required Properties
+	// Keys are tracked in the order they are generated in so property order can be preserved
 	generatedValues := make(map[string]interface{})
+	generatedKeys := []string{}
+	set := func(key string, value interface{}) {
+		if _, exists := generatedValues[key]; !exists {
+			generatedKeys = append(generatedKeys, key)
+		}
+
+		generatedValues[key] = value
+	}
+
+	// Generate Required Properties
 	for _, key := range g.Required {
 		// If no properties are defined, generate a nil value
-		if g.Properties == nil {
-			generatedValues[key] = fmt.Sprintf("required_%s_%d", key, opts.Rand.RandomInt(0, 9999999))
-		} else if _, ok := g.Properties[key]; !ok {
-			generatedValues[key] = fmt.Sprintf("required_%s_%d", key, opts.Rand.RandomInt(0, 9999999))
+		if _, ok := g.Properties[key]; !ok {
+			set(key, generateFillerAtPath(opts, key, TraceFillerRequired, constGenerator{Value: fmt.Sprintf("required_%s_%d", key, opts.Rand.RandomInt(0, 9999999))}))
 		} else {
 			// Generate the required property
-			generatedValues[key] = g.Properties[key].Generate(opts)
+			set(key, generateAtPath(opts, key, g.Properties[key]))
 		}
 	}
 
 	// Generate A random distribution of optional properties, pattern properties, and additional properties
 	// (Using a fallback generator if none are available)
-	optionalKeys := funk.UniqString(append(g.Required, funk.Keys(g.Properties).([]string)...))
+	// (Keys are sorted so generation is reproducible for a given source of randomness)
+	optionalKeys := funk.UniqString(append(g.Required, util.MapKeysToStringSlice(&g.Properties)...))
+	sort.Strings(optionalKeys)
+
+	// Optional properties with an explicit probability are decided independently of the rest
+	probabilityKeys := []string{}
+	if len(g.PropertyProbabilities) > 0 {
+		optionalKeys = funk.FilterString(optionalKeys, func(key string) bool {
+			_, hasProbability := g.PropertyProbabilities[key]
+			if hasProbability && !funk.ContainsString(g.Required, key) {
+				probabilityKeys = append(probabilityKeys, key)
+				return false
+			}
+
+			return true
+		})
+		sort.Strings(probabilityKeys)
+	}
 
 	min := util.GetInt(g.MinProperties, opts.DefaultObjectMinProperties)
 	max := util.GetInt(g.MaxProperties, opts.DefaultObjectMaxProperties)
@@ -204,30 +268,42 @@ func (g objectGenerator) Generate(opts *GeneratorOptions) interface{} {
 
 	// Generate any optional keys
 	for _, key := range optionalKeysToGenerate {
-		if g.Properties == nil {
-			generatedValues[key] = fmt.Sprintf("optional_%s_%d", key, opts.Rand.RandomInt(0, 9999999))
-		} else if _, ok := g.Properties[key]; !ok {
-			generatedValues[key] = fmt.Sprintf("optional_%s_%d", key, opts.Rand.RandomInt(0, 9999999))
+		if _, ok := g.Properties[key]; !ok {
+			set(key, generateFillerAtPath(opts, key, TraceFillerOptional, constGenerator{Value: fmt.Sprintf("optional_%s_%d", key, opts.Rand.RandomInt(0, 9999999))}))
 		} else {
-			generatedValues[key] = g.Properties[key].Generate(opts)
+			set(key, generateAtPath(opts, key, g.Properties[key]))
 		}
 	}
 
 	generatorTarget -= len(optionalKeysToGenerate)
 
+	for _, key := range probabilityKeys {
+		if g.MaxProperties > 0 && len(generatedValues) >= g.MaxProperties {
+			break
+		}
+
+		if opts.Rand.RandomFloat(0, 1) >= g.PropertyProbabilities[key] {
+			continue
+		}
+
+		set(key, generateAtPath(opts, key, g.Properties[key]))
+		generatorTarget = util.MaxInt(0, generatorTarget-1)
+	}
+
 	// Generate any pattern properties
 	// Failing that generate any additional properties
 	// Failing that generate any fallback properties
 	if len(g.PatternProperties) > 0 {
 		for i := 0; i < generatorTarget; i++ {
 			regex, value := g.GeneratePatternProperty(opts)
-			generatedValues[regex] = value
+			set(regex, value)
 		}
 	} else if g.DisallowAdditionalProperties {
-		return generatedValues
+		return g.withPropertyOrder(opts, generatedValues, generatedKeys)
 	} else if g.AdditionalProperties != nil {
 		for i := 0; i < generatorTarget; i++ {
-			generatedValues[fmt.Sprintf("additional_%d", i)] = g.AdditionalProperties.Generate(opts)
+			key := fmt.Sprintf("additional_%d", i)
+			set(key, generateAtPath(opts, key, g.AdditionalProperties))
 		}
 	} else {
 		for i := 0; i < generatorTarget; i++ {
@@ -235,7 +311,8 @@ func (g objectGenerator) Generate(opts *GeneratorOptions) interface{} {
 				continue
 			}
 
-			generatedValues[fmt.Sprintf("fallback_%d", i)] = g.FallbackGenerator.Generate(opts)
+			key := fmt.Sprintf("fallback_%d", i)
+			set(key, generateFillerAtPath(opts, key, TraceFillerFallback, g.FallbackGenerator))
 		}
 	}
 
@@ -249,12 +326,36 @@ func (g objectGenerator) Generate(opts *GeneratorOptions) interface{} {
 		}
 
 		for i := len(generatedValues); i < min; i++ {
-			generatedValues[fmt.Sprintf("min_filler_%d", i)] = generator.Generate(opts)
+			key := fmt.Sprintf("min_filler_%d", i)
+			set(key, generateFillerAtPath(opts, key, TraceFillerMinProperties, generator))
+		}
+
+	}
+
+	return g.withPropertyOrder(opts, generatedValues, generatedKeys)
+}
+
+// Returns the object as an OrderedObject with declared properties in schema order followed by any others
+// (e.g. pattern and additional properties) in the order they were generated in if property order is being preserved
+func (g objectGenerator) withPropertyOrder(opts *GeneratorOptions, values map[string]interface{}, generatedKeys []string) interface{} {
+	if !opts.PreservePropertyOrder {
+		return values
+	}
+
+	keys := make([]string, 0, len(values))
+	for _, key := range g.PropertyOrder {
+		if _, ok := values[key]; ok {
+			keys = append(keys, key)
 		}
+	}
 
+	for _, key := range generatedKeys {
+		if _, declared := g.Properties[key]; !declared {
+			keys = append(keys, key)
+		}
 	}
 
-	return generatedValues
+	return OrderedObject{Keys: keys, Values: values}
 }
 
 func (g objectGenerator) GeneratePatternProperty(opts *GeneratorOptions) (string, interface{}) {
@@ -262,7 +363,8 @@ func (g objectGenerator) GeneratePatternProperty(opts *GeneratorOptions) (string
 		return "", nil
 	}
 
-	availableRegexes := funk.Keys(g.PatternProperties).([]string)
+	availableRegexes := util.MapKeysToStringSlice(&g.PatternProperties)
+	sort.Strings(availableRegexes)
 	targetRegex := opts.Rand.StringChoice(&availableRegexes)
 	targetRegexGenerator := g.PatternPropertiesRegex[targetRegex]
 	targetGenerator := g.PatternProperties[targetRegex]
@@ -271,7 +373,8 @@ func (g objectGenerator) GeneratePatternProperty(opts *GeneratorOptions) (string
 		return "", nil
 	}
 
-	return targetRegexGenerator.Generate(), targetGenerator.Generate(opts)
+	key := targetRegexGenerator.Generate()
+	return key, generateAtPath(opts, key, targetGenerator)
 }
 
 func (g objectGenerator) String() string {
@@ -287,3 +390,13 @@ func (g objectGenerator) String() string {
 
 	return fmt.Sprintf("ObjectGenerator{properties: %s, patternProperties: %s, additionalProperties: %v}", formattedString, regexString, g.DisallowAdditionalProperties)
 }
+
+func (g objectGenerator) describe(node *GeneratorNode) {
+	node.Kind = GeneratorKindObject
+	node.Required = g.Required
+	node.Bounds.MinProperties = intBound(g.MinProperties)
+	node.Bounds.MaxProperties = intBound(g.MaxProperties)
+	node.addChildren("properties", g.Properties)
+	node.addChildren("patternProperties", g.PatternProperties)
+	node.addChild("additionalProperties", "", g.AdditionalProperties)
+}
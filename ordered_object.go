@@ -0,0 +1,174 @@
+package chaff
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// A generated object that keeps its properties in order when encoded as JSON or YAML
+	// (See GeneratorOptions.PreservePropertyOrder)
+	OrderedObject struct {
+		Keys   []string
+		Values map[string]interface{}
+	}
+)
+
+func (o OrderedObject) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range o.Keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		encodedValue, err := json.Marshal(o.Values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(encodedKey)
+		buffer.WriteByte(':')
+		buffer.Write(encodedValue)
+	}
+
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+func (o OrderedObject) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, key := range o.Keys {
+		keyNode := &yaml.Node{}
+		if err := keyNode.Encode(key); err != nil {
+			return nil, err
+		}
+
+		valueNode := &yaml.Node{}
+		if err := valueNode.Encode(o.Values[key]); err != nil {
+			return nil, err
+		}
+
+		node.Content = append(node.Content, keyNode, valueNode)
+	}
+
+	return node, nil
+}
+
+// Returns a generated value with its OrderedObjects replaced by maps for code that only understands the values
+// encoding/json decodes to (e.g. validating the value against a schema or comparing values by their JSON).
+// Values without any OrderedObjects are returned as they are
+func unorderedValue(value interface{}) interface{} {
+	if !containsOrderedObject(value) {
+		return value
+	}
+
+	switch value := value.(type) {
+	case OrderedObject:
+		object := make(map[string]interface{}, len(value.Values))
+		for key, child := range value.Values {
+			object[key] = unorderedValue(child)
+		}
+
+		return object
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for key, child := range value {
+			object[key] = unorderedValue(child)
+		}
+
+		return object
+	case []interface{}:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = unorderedValue(item)
+		}
+
+		return items
+	default:
+		return value
+	}
+}
+
+func containsOrderedObject(value interface{}) bool {
+	switch value := value.(type) {
+	case OrderedObject:
+		return true
+	case map[string]interface{}:
+		for _, child := range value {
+			if containsOrderedObject(child) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if containsOrderedObject(item) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Decodes JSON keeping the order of object keys (as OrderedObjects) and numbers as they were written
+func decodeOrderedJson(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decodeOrderedJsonValue(decoder)
+}
+
+func decodeOrderedJsonValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := OrderedObject{Keys: []string{}, Values: map[string]interface{}{}}
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			key, _ := keyToken.(string)
+			value, err := decodeOrderedJsonValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, exists := object.Values[key]; !exists {
+				object.Keys = append(object.Keys, key)
+			}
+
+			object.Values[key] = value
+		}
+
+		_, err := decoder.Token()
+		return object, err
+	case json.Delim('['):
+		items := []interface{}{}
+		for decoder.More() {
+			item, err := decodeOrderedJsonValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+
+		_, err := decoder.Token()
+		return items, err
+	default:
+		return token, nil
+	}
+}
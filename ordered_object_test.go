@@ -0,0 +1,189 @@
+package chaff_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+func getJsonKeys(t *testing.T, data []byte) []string {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		t.Fatalf("Failed to decode output: %s", err)
+	}
+
+	keys := []string{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			t.Fatalf("Failed to decode output: %s", err)
+		}
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			t.Fatalf("Failed to decode output: %s", err)
+		}
+
+		keys = append(keys, token.(string))
+	}
+
+	return keys
+}
+
+func TestPreservePropertyOrder(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{
+		"type": "object",
+		"properties": {
+			"zulu": {"const": 1},
+			"alpha": {
+				"type": "object",
+				"properties": {
+					"second": {"const": 2},
+					"first": {"const": 1}
+				},
+				"required": ["second", "first"]
+			},
+			"mike": {"const": 3}
+		},
+		"patternProperties": {
+			"^x_[0-9]$": {"const": true}
+		},
+		"required": ["mike", "alpha", "zulu"],
+		"minProperties": 5,
+		"maxProperties": 5
+	}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		result := generator.Generate(&chaff.GeneratorOptions{PreservePropertyOrder: true})
+		if _, ok := result.(chaff.OrderedObject); !ok {
+			t.Fatalf("Expected an ordered object, got %T", result)
+		}
+
+		output, err := json.Marshal(result)
+		if err != nil {
+			t.Fatalf("Failed to marshal output: %s", err)
+		}
+
+		keys := getJsonKeys(t, output)
+		if len(keys) != 5 || keys[0] != "zulu" || keys[1] != "alpha" || keys[2] != "mike" {
+			t.Fatalf("Expected declared properties in schema order, got %s", output)
+		}
+
+		for _, key := range keys[3:] {
+			if key == "zulu" || key == "alpha" || key == "mike" {
+				t.Fatalf("Expected undeclared properties after declared properties, got %s", output)
+			}
+		}
+
+		var nested struct {
+			Alpha json.RawMessage `json:"alpha"`
+		}
+		if err := json.Unmarshal(output, &nested); err != nil {
+			t.Fatalf("Failed to decode output: %s", err)
+		}
+
+		if string(nested.Alpha) != `{"second":2,"first":1}` {
+			t.Fatalf("Expected nested properties in schema order, got %s", nested.Alpha)
+		}
+	}
+}
+
+func TestPreservePropertyOrderYaml(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`
+type: object
+properties:
+  name:
+    const: build
+  image:
+    const: golang
+  steps:
+    const: [go build]
+required: [steps, image, name]
+additionalProperties: false
+`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	result := generator.Generate(&chaff.GeneratorOptions{PreservePropertyOrder: true})
+	output, err := chaff.MarshalOutput(result, chaff.OutputFormatYAML)
+	if err != nil {
+		t.Fatalf("Failed to marshal output: %s", err)
+	}
+
+	expected := `name: build
+image: golang
+steps:
+  - go build
+`
+	if string(output) != expected {
+		t.Fatalf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+}
+
+func TestPreservePropertyOrderConstraints(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{
+		"type": "object",
+		"properties": {"kind": {"enum": ["a", "b"]}, "size": {"enum": [1, 2]}},
+		"required": ["kind", "size"],
+		"additionalProperties": false,
+		"if": {"properties": {"kind": {"const": "a"}}},
+		"then": {"properties": {"size": {"const": 1}}},
+		"else": {"properties": {"size": {"const": 2}}}
+	}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	// Ordered objects are validated like any other object
+	for i := 0; i < 20; i++ {
+		result, ok := generator.Generate(&chaff.GeneratorOptions{PreservePropertyOrder: true}).(chaff.OrderedObject)
+		if !ok {
+			t.Fatalf("Expected an ordered object, got %T", result)
+		}
+
+		if (result.Values["kind"] == "a") != (result.Values["size"] == float64(1)) {
+			t.Fatalf("Expected the if condition to be checked against the ordered object, got %v", result.Values)
+		}
+	}
+
+	generator, err = chaff.ParseSchemaStringWithDefaults(`{"oneOf": [
+		{"type": "object", "properties": {"kind": {"const": "a"}}, "required": ["kind"]},
+		{"type": "object", "properties": {"kind": {"type": "string"}}, "required": ["kind"], "minProperties": 3}
+	]}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		result := generator.Generate(&chaff.GeneratorOptions{PreservePropertyOrder: true})
+		if _, ok := result.(chaff.OrderedObject); !ok {
+			t.Fatalf("Expected the oneOf constraint to be checked against the ordered object, got %v", result)
+		}
+	}
+}
+
+func TestPreservePropertyOrderDisabled(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{
+		"type": "object",
+		"properties": {"b": {"const": 1}, "a": {"const": 2}},
+		"required": ["b", "a"]
+	}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	result := generator.Generate(&chaff.GeneratorOptions{})
+	if _, ok := result.(map[string]interface{}); !ok {
+		t.Fatalf("Expected a map, got %T", result)
+	}
+}
@@ -0,0 +1,315 @@
+package chaff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Encoding generated values can be written in (See MarshalOutput)
+	OutputFormat string
+
+	// Integer too large for an int64 (e.g. a json.Number beyond 2^63) written with all of its digits
+	outputInteger string
+
+	// Float written to YAML with a fractional part (e.g. "2.0" rather than "2") so it is not read back as an integer
+	outputFloat float64
+)
+
+const (
+	// Compact JSON
+	OutputFormatJSON OutputFormat = "json"
+
+	// Canonical JSON (RFC 8785) for byte stable output (See MarshalCanonical)
+	OutputFormatCanonical OutputFormat = "canonical"
+
+	// Indented JSON for files read as JSON with comments (e.g. "tsconfig.json" or ".vscode/settings.json")
+	OutputFormatJSONC OutputFormat = "jsonc"
+
+	// YAML with keys in the order they are written to JSON (sorted unless GeneratorOptions.PreservePropertyOrder is set)
+	OutputFormatYAML OutputFormat = "yaml"
+
+	// TOML with keys in sorted order. TOML has no null so null properties are left out
+	OutputFormatTOML OutputFormat = "toml"
+)
+
+// Returns the formats supported by MarshalOutput
+func OutputFormats() []string {
+	return []string{string(OutputFormatJSON), string(OutputFormatCanonical), string(OutputFormatJSONC), string(OutputFormatYAML), string(OutputFormatTOML)}
+}
+
+// Returns the output format for a file path based on its extension (e.g. "yaml" for "Taskfile.yml").
+// Returns false if the extension does not belong to any of the supported formats
+func GetOutputFormatForPath(path string) (OutputFormat, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return OutputFormatJSON, true
+	case ".jsonc":
+		return OutputFormatJSONC, true
+	case ".yaml", ".yml":
+		return OutputFormatYAML, true
+	case ".toml":
+		return OutputFormatTOML, true
+	default:
+		return "", false
+	}
+}
+
+// Encodes a generated value in the given format. Integers (e.g. generated for "integer" schemas) are written
+// as integers and floats (e.g. generated for "number" schemas) as floats even without a fractional part so
+// integer and number properties keep their types in formats that tell them apart
+func MarshalOutput(value interface{}, format OutputFormat) ([]byte, error) {
+	switch format {
+	case OutputFormatJSON:
+		return json.Marshal(value)
+	case OutputFormatCanonical:
+		return MarshalCanonical(value)
+	case OutputFormatJSONC:
+		return json.MarshalIndent(value, "", "    ")
+	}
+
+	normalized, err := normalizeOutputValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case OutputFormatYAML:
+		var buffer bytes.Buffer
+		encoder := yaml.NewEncoder(&buffer)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(normalized); err != nil {
+			return nil, fmt.Errorf("failed to encode output as yaml: %w", err)
+		}
+
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode output as yaml: %w", err)
+		}
+
+		return buffer.Bytes(), nil
+	case OutputFormatTOML:
+		object, ok := normalized.(OrderedObject)
+		if !ok {
+			return nil, fmt.Errorf("only objects can be written as toml, got %s", describeOutputValue(normalized))
+		}
+
+		table, err := removeTomlNulls(object, "#")
+		if err != nil {
+			return nil, err
+		}
+
+		output, err := toml.Marshal(table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode output as toml: %w", err)
+		}
+
+		return output, nil
+	default:
+		return nil, fmt.Errorf("unknown output format '%s' (supported formats: %s)", format, strings.Join(OutputFormats(), ", "))
+	}
+}
+
+// Returns the value as OrderedObjects, slices, strings, booleans, int64s, outputIntegers and outputFloats.
+// Numbers keep the type they were generated as (e.g. int for "integer" schemas and float64 for "number"
+// schemas) so floats without a fractional part are not written as integers. Objects keep the order their keys
+// are written to JSON in and values of other types (e.g. structs) are read from the JSON they are written as
+func normalizeOutputValue(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case nil, string, bool:
+		return value, nil
+	case int:
+		return int64(value), nil
+	case int8:
+		return int64(value), nil
+	case int16:
+		return int64(value), nil
+	case int32:
+		return int64(value), nil
+	case int64:
+		return value, nil
+	case uint:
+		return normalizeOutputUint(uint64(value)), nil
+	case uint8:
+		return int64(value), nil
+	case uint16:
+		return int64(value), nil
+	case uint32:
+		return int64(value), nil
+	case uint64:
+		return normalizeOutputUint(value), nil
+	case float32:
+		return normalizeOutputFloat(float64(value))
+	case float64:
+		return normalizeOutputFloat(value)
+	case json.Number:
+		return normalizeOutputNumber(value), nil
+	case OrderedObject:
+		object := OrderedObject{Keys: value.Keys, Values: make(map[string]interface{}, len(value.Values))}
+		for key, child := range value.Values {
+			normalized, err := normalizeOutputValue(child)
+			if err != nil {
+				return nil, err
+			}
+
+			object.Values[key] = normalized
+		}
+
+		return object, nil
+	case map[string]interface{}:
+		object := OrderedObject{Keys: make([]string, 0, len(value)), Values: make(map[string]interface{}, len(value))}
+		for key, child := range value {
+			normalized, err := normalizeOutputValue(child)
+			if err != nil {
+				return nil, err
+			}
+
+			object.Keys = append(object.Keys, key)
+			object.Values[key] = normalized
+		}
+
+		sort.Strings(object.Keys)
+		return object, nil
+	case []interface{}:
+		array := make([]interface{}, len(value))
+		for i, child := range value {
+			normalized, err := normalizeOutputValue(child)
+			if err != nil {
+				return nil, err
+			}
+
+			array[i] = normalized
+		}
+
+		return array, nil
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		decoded, err := decodeOrderedJson(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return normalizeOutputValue(decoded)
+	}
+}
+
+func normalizeOutputUint(value uint64) interface{} {
+	if value > math.MaxInt64 {
+		return outputInteger(strconv.FormatUint(value, 10))
+	}
+
+	return int64(value)
+}
+
+func normalizeOutputFloat(value float64) (interface{}, error) {
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return nil, &json.UnsupportedValueError{Value: reflect.ValueOf(value), Str: strconv.FormatFloat(value, 'g', -1, 64)}
+	}
+
+	return outputFloat(value), nil
+}
+
+// Numbers decoded from JSON are integers unless they are written with a fraction or an exponent
+func normalizeOutputNumber(value json.Number) interface{} {
+	if integer, err := value.Int64(); err == nil {
+		return integer
+	}
+
+	if _, ok := new(big.Int).SetString(value.String(), 10); ok {
+		return outputInteger(value)
+	}
+
+	float, err := value.Float64()
+	if err != nil {
+		return value.String()
+	}
+
+	return outputFloat(float)
+}
+
+// Leaves out null properties as TOML can not represent them. Nulls in arrays can not be left out without
+// shifting the items after them so they are reported as an error instead
+func removeTomlNulls(value interface{}, pointer string) (interface{}, error) {
+	switch value := value.(type) {
+	case OrderedObject:
+		table := make(map[string]interface{}, len(value.Values))
+		for key, child := range value.Values {
+			if child == nil {
+				continue
+			}
+
+			converted, err := removeTomlNulls(child, pointer+"/"+escapeJsonPointerSegment(key))
+			if err != nil {
+				return nil, err
+			}
+
+			table[key] = converted
+		}
+
+		return table, nil
+	case outputInteger:
+		return nil, fmt.Errorf("integer %s at '%s' does not fit a 64-bit toml integer", value, pointer)
+	case outputFloat:
+		return float64(value), nil
+	case []interface{}:
+		array := make([]interface{}, len(value))
+		for i, child := range value {
+			if child == nil {
+				return nil, fmt.Errorf("null at '%s/%d' can not be written as toml", pointer, i)
+			}
+
+			converted, err := removeTomlNulls(child, fmt.Sprintf("%s/%d", pointer, i))
+			if err != nil {
+				return nil, err
+			}
+
+			array[i] = converted
+		}
+
+		return array, nil
+	default:
+		return value, nil
+	}
+}
+
+func (i outputInteger) MarshalYAML() (interface{}, error) {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: string(i)}, nil
+}
+
+func (f outputFloat) MarshalYAML() (interface{}, error) {
+	formatted := strconv.FormatFloat(float64(f), 'g', -1, 64)
+	if !strings.ContainsAny(formatted, ".e") {
+		formatted += ".0"
+	}
+
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: formatted}, nil
+}
+
+func describeOutputValue(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	default:
+		return "a number"
+	}
+}
@@ -0,0 +1,136 @@
+package chaff_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+// Values as they are generated: ints for "integer" schemas and float64s for "number" schemas
+func getOutputTestValue() interface{} {
+	return map[string]interface{}{
+		"name":    "build",
+		"retries": 3,
+		"ratio":   0.5,
+		"whole":   2.0,
+		"enabled": true,
+		"skip":    nil,
+		"tags":    []interface{}{"ci", "yes"},
+		"steps": []interface{}{
+			map[string]interface{}{"run": "go build"},
+			map[string]interface{}{"run": "go test"},
+		},
+	}
+}
+
+func TestMarshalOutputYaml(t *testing.T) {
+	t.Parallel()
+	output, err := chaff.MarshalOutput(getOutputTestValue(), chaff.OutputFormatYAML)
+	if err != nil {
+		t.Fatalf("Failed to marshal output: %s", err)
+	}
+
+	expected := `enabled: true
+name: build
+ratio: 0.5
+retries: 3
+skip: null
+steps:
+  - run: go build
+  - run: go test
+tags:
+  - ci
+  - "yes"
+whole: 2.0
+`
+	if string(output) != expected {
+		t.Errorf("Expected yaml output:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestMarshalOutputToml(t *testing.T) {
+	t.Parallel()
+	output, err := chaff.MarshalOutput(getOutputTestValue(), chaff.OutputFormatTOML)
+	if err != nil {
+		t.Fatalf("Failed to marshal output: %s", err)
+	}
+
+	expected := `enabled = true
+name = 'build'
+ratio = 0.5
+retries = 3
+tags = ['ci', 'yes']
+whole = 2.0
+
+[[steps]]
+run = 'go build'
+
+[[steps]]
+run = 'go test'
+`
+	if string(output) != expected {
+		t.Errorf("Expected toml output:\n%s\ngot:\n%s", expected, output)
+	}
+
+	if _, err := chaff.MarshalOutput([]interface{}{"a"}, chaff.OutputFormatTOML); err == nil || !strings.Contains(err.Error(), "only objects") {
+		t.Errorf("Expected arrays to be rejected as toml documents, got %v", err)
+	}
+
+	if _, err := chaff.MarshalOutput(map[string]interface{}{"a": []interface{}{1, nil}}, chaff.OutputFormatTOML); err == nil || !strings.Contains(err.Error(), "'#/a/1'") {
+		t.Errorf("Expected nulls in arrays to be reported, got %v", err)
+	}
+}
+
+func TestMarshalOutputNumberTypes(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{
+		"type": "object",
+		"properties": {
+			"count": {"type": "integer", "minimum": 1, "maximum": 9},
+			"size": {"type": "number", "minimum": 1, "maximum": 9, "multipleOf": 1}
+		},
+		"required": ["count", "size"],
+		"additionalProperties": false
+	}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	// Floats without a fractional part stay floats and integers stay integers
+	expected := regexp.MustCompile(`^count = [1-9]\nsize = [1-9]\.0\n$`)
+	for i := 0; i < 20; i++ {
+		output, err := chaff.MarshalOutput(generator.GenerateWithDefaults(), chaff.OutputFormatTOML)
+		if err != nil {
+			t.Fatalf("Failed to marshal output: %s", err)
+		}
+
+		if !expected.Match(output) {
+			t.Fatalf("Expected an integer count and a float size, got:\n%s", output)
+		}
+	}
+}
+
+func TestMarshalOutputFormats(t *testing.T) {
+	t.Parallel()
+	for path, expected := range map[string]chaff.OutputFormat{
+		"config.json":            chaff.OutputFormatJSON,
+		".vscode/settings.jsonc": chaff.OutputFormatJSONC,
+		"Taskfile.yml":           chaff.OutputFormatYAML,
+		"dependabot.YAML":        chaff.OutputFormatYAML,
+		"pyproject.toml":         chaff.OutputFormatTOML,
+	} {
+		if format, ok := chaff.GetOutputFormatForPath(path); !ok || format != expected {
+			t.Errorf("Expected '%s' to be written as %s, got %s", path, expected, format)
+		}
+	}
+
+	if _, ok := chaff.GetOutputFormatForPath("output.txt"); ok {
+		t.Errorf("Expected no format to be inferred for unknown extensions")
+	}
+
+	if _, err := chaff.MarshalOutput("value", chaff.OutputFormat("xml")); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
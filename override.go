@@ -0,0 +1,177 @@
+package chaff
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ryanolee/go-chaff/rand"
+)
+
+type (
+	// Callback used to produce the value for an overridden path.
+	// Example:
+	//
+	//	chaff.OverrideFunc(func(ctx chaff.OverrideContext) interface{} {
+	//		return ctx.Rand.RandomInt(1, 100) * 100
+	//	})
+	OverrideFunc func(ctx OverrideContext) interface{}
+
+	// Context passed to an OverrideFunc
+	OverrideContext struct {
+		// JSON pointer of the value being generated (e.g. "/items/3/price")
+		Path string
+
+		// The source of randomness used for generation
+		Rand *rand.RandUtil
+
+		// The generator that would have produced the value had there been no override
+		Generator Generator
+
+		// The options generation is running with
+		Options *GeneratorOptions
+	}
+
+	// An override with its JSON pointer split into unescaped segments
+	compiledOverride struct {
+		pointer   string
+		segments  []string
+		wildcards int
+		value     interface{}
+	}
+)
+
+// Segment of an override pointer matching any single object key or array index
+const overrideWildcard = "*"
+
+// Generates the value the generator would have produced without the override
+func (ctx OverrideContext) Default() interface{} {
+	return ctx.Generator.Generate(ctx.Options)
+}
+
+// Compiles the overrides given through the generator options. Pointers that match fewer
+// segments through wildcards take precedence over those that match more
+// e.g "/items/0/price" is used over "/items/*/price". Keys that are not JSON pointers are ignored
+func compileOverrides(overrides map[string]interface{}) []compiledOverride {
+	compiled := make([]compiledOverride, 0, len(overrides))
+	for pointer, value := range overrides {
+		trimmed := strings.TrimPrefix(pointer, "#")
+		if trimmed != "" && !strings.HasPrefix(trimmed, "/") {
+			continue
+		}
+
+		segments := []string{}
+		wildcards := 0
+		if trimmed != "" {
+			segments = strings.Split(trimmed[1:], "/")
+		}
+
+		for i, segment := range segments {
+			if segment == overrideWildcard {
+				wildcards++
+				continue
+			}
+
+			segments[i] = unescapeJsonPointerSegment(segment)
+		}
+
+		compiled = append(compiled, compiledOverride{
+			pointer:   pointer,
+			segments:  segments,
+			wildcards: wildcards,
+			value:     value,
+		})
+	}
+
+	sort.Slice(compiled, func(i, j int) bool {
+		if compiled[i].wildcards != compiled[j].wildcards {
+			return compiled[i].wildcards < compiled[j].wildcards
+		}
+
+		return compiled[i].pointer < compiled[j].pointer
+	})
+
+	return compiled
+}
+
+// Generates the value at the given object key or array index using the generator,
+// unless an override matches the path in which case the override is used instead
+func generateAtPath(opts *GeneratorOptions, segment string, generator Generator) interface{} {
+	if len(opts.overrides) == 0 && opts.trace == nil {
+		return generator.Generate(opts)
+	}
+
+	opts.instancePath = append(opts.instancePath, segment)
+	defer func() { opts.instancePath = opts.instancePath[:len(opts.instancePath)-1] }()
+
+	return generateWithOverrides(opts, generator)
+}
+
+// Generates a value for the current path checking for any matching override
+func generateWithOverrides(opts *GeneratorOptions, generator Generator) interface{} {
+	override, ok := findOverride(opts.overrides, opts.instancePath)
+	if !ok {
+		return generator.Generate(opts)
+	}
+
+	if opts.trace != nil {
+		done := traceOverride(opts, generator)
+		defer done()
+	}
+
+	callback, isCallback := override.value.(OverrideFunc)
+	if !isCallback {
+		callback, isCallback = override.value.(func(OverrideContext) interface{})
+	}
+
+	if !isCallback {
+		return override.value
+	}
+
+	return callback(OverrideContext{
+		Path:      formatJsonPointer(opts.instancePath),
+		Rand:      opts.Rand,
+		Generator: generator,
+		Options:   opts,
+	})
+}
+
+func findOverride(overrides []compiledOverride, path []string) (compiledOverride, bool) {
+	for _, override := range overrides {
+		if len(override.segments) != len(path) {
+			continue
+		}
+
+		matched := true
+		for i, segment := range override.segments {
+			if segment != overrideWildcard && segment != path[i] {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return override, true
+		}
+	}
+
+	return compiledOverride{}, false
+}
+
+func formatJsonPointer(path []string) string {
+	var sb strings.Builder
+	for _, segment := range path {
+		sb.WriteString("/")
+		sb.WriteString(escapeJsonPointerSegment(segment))
+	}
+
+	return sb.String()
+}
+
+// Escapes a segment as per RFC 6901 ("~" -> "~0", "/" -> "~1")
+func escapeJsonPointerSegment(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1")
+}
+
+func unescapeJsonPointerSegment(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+}
@@ -0,0 +1,95 @@
+package chaff_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+	test "github.com/ryanolee/go-chaff/internal/test_utils"
+)
+
+func TestOverrides(t *testing.T) {
+	t.Parallel()
+	test.TestJsonSchemaDirWithConfig(t, "test_data/override", 50, nil, func() *chaff.GeneratorOptions {
+		return &chaff.GeneratorOptions{
+			Overrides: map[string]interface{}{
+				"/tenantId": "acme",
+				"/items/*/price": chaff.OverrideFunc(func(ctx chaff.OverrideContext) interface{} {
+					return ctx.Rand.RandomInt(1, 1000) * 100
+				}),
+				"/payment/kind": "bank",
+			},
+		}
+	})
+}
+
+func TestOverridesValues(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaFileWithDefaults("test_data/override/override.json")
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	var mutex sync.Mutex
+	paths := map[string]bool{}
+	opts := &chaff.GeneratorOptions{
+		Overrides: map[string]interface{}{
+			"/tenantId":       "acme",
+			"/items/0/price":  0,
+			"/items/*/price":  func(ctx chaff.OverrideContext) interface{} { return 42 },
+			"/payment/kind":   "bank",
+			"/missing/*/path": "never used",
+			"/items/*/sku": chaff.OverrideFunc(func(ctx chaff.OverrideContext) interface{} {
+				mutex.Lock()
+				paths[ctx.Path] = true
+				mutex.Unlock()
+
+				return strings.ToLower(ctx.Default().(string))
+			}),
+		},
+	}
+
+	for i := 0; i < 20; i++ {
+		value := generator.Generate(opts).(map[string]interface{})
+		if value["tenantId"] != "acme" {
+			t.Fatalf("Expected tenantId to be overridden, got %v", value["tenantId"])
+		}
+
+		items := value["items"].([]interface{})
+		for index, item := range items {
+			price := item.(map[string]interface{})["price"]
+			if (index == 0 && price != 0) || (index > 0 && price != 42) {
+				t.Fatalf("Expected the price of item %d to be overridden, got %v", index, price)
+			}
+
+			sku := item.(map[string]interface{})["sku"].(string)
+			if sku != strings.ToLower(sku) || len(sku) != 8 {
+				t.Fatalf("Expected sku to be delegated to the default generator and lower cased, got %s", sku)
+			}
+		}
+
+		// The oneOf has to pick the branch that matches the overridden value
+		payment := value["payment"].(map[string]interface{})
+		if payment["kind"] != "bank" || payment["iban"] == nil {
+			t.Fatalf("Expected the bank payment branch, got %v", payment)
+		}
+	}
+
+	if !paths["/items/0/sku"] || !paths["/items/1/sku"] {
+		t.Fatalf("Expected callbacks to receive the path of the value, got %v", paths)
+	}
+}
+
+func TestOverridesRoot(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{"type": "string"}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	value := generator.Generate(&chaff.GeneratorOptions{Overrides: map[string]interface{}{"": "root"}})
+	if value != "root" {
+		t.Fatalf("Expected the root value to be overridden, got %v", value)
+	}
+}
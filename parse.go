@@ -3,8 +3,11 @@ package chaff
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
+	"net/http"
 	"os"
 	"regexp/syntax"
+	"time"
 
 	"github.com/ryanolee/go-chaff/internal/regen"
 	"github.com/ryanolee/go-chaff/internal/util"
@@ -25,9 +28,37 @@ type (
 		// Base path to resolve relative document references against for $ref resolution when fetching external documents
 		RelativeTo string `json:"relativeTo,omitempty" jsonschema:"title=Relative To"`
 
+		// Documents "$ref"s can resolve to without any fetching, keyed by their URI (Relative URIs are resolved against RelativeTo).
+		// Documents can also be referenced by their root "$id"
+		Documents map[string][]byte `json:"-"`
+
 		// Maximum recursion depth during parsing to prevent stack overflow from circular schemas.
 		// If zero, defaults to 100.
 		MaxParseDepth int `json:"maxParseDepth,omitempty" jsonschema:"title=Max Parse Depth"`
+
+		// Infer realistic values for strings without a "format" or "pattern" from their property name,
+		// "title" or "description" (e.g. an "email" property will be given an email address)
+		InferStringSemantics bool `json:"inferStringSemantics,omitempty" jsonschema:"title=Infer String Semantics"`
+
+		// Rules used to infer string values when InferStringSemantics is enabled.
+		// If empty, DefaultSemanticRules() is used.
+		SemanticRules []SemanticRule `json:"-"`
+
+		// Record every keyword that is ignored during generation (e.g. "propertyNames" or a misspelt "minLenght")
+		// in the diagnostics along with its path. Annotation keywords ("title", "description", "$comment", "x-*" etc.)
+		// are always allowed
+		Strict bool `json:"strict,omitempty" jsonschema:"title=Strict"`
+
+		// Fail parsing if Strict is enabled and any ignored keywords are found
+		StrictFailOnUnknownKeywords bool `json:"strictFailOnUnknownKeywords,omitempty" jsonschema:"title=Strict Fail On Unknown Keywords"`
+
+		// Additional keywords to treat as annotations when Strict is enabled (e.g. the keywords of a custom vocabulary)
+		StrictAllowedKeywords []string `json:"strictAllowedKeywords,omitempty" jsonschema:"title=Strict Allowed Keywords"`
+
+		// Validate the schema and every external document it references against the meta-schema of their dialect
+		// (Given by "$schema", defaulting to draft 2020-12) before parsing them. An invalid schema fails parsing with
+		// a *SchemaValidationError and invalid external documents are reported in the diagnostics
+		ValidateSchema bool `json:"validateSchema,omitempty" jsonschema:"title=Validate Schema"`
 	}
 
 	// Options for fetching external documents during parsing.
@@ -38,6 +69,15 @@ type (
 
 		// File System Fetch Options
 		FileSystemFetchOptions FileSystemFetchOptions `json:"fileSystemFetchOptions,omitempty" jsonschema:"title=File System Document Resolver Options"`
+
+		// Fetchers for documents by the scheme of their URI (e.g. "registry" for "registry://schemas/order.json").
+		// These take precedence over the built in "http", "https" and "file" fetchers. A nil fetcher disables the scheme
+		Fetchers map[string]DocumentFetcher `json:"-"`
+
+		// Maximum number of queued documents to fetch at once in the background while other documents are parsed.
+		// Documents are still parsed one at a time in the order they were found (If zero or one, each document is
+		// only fetched once it is parsed). Fetchers in Fetchers must be safe for concurrent use if this is above one
+		Concurrency int `json:"concurrency,omitempty" jsonschema:"title=Concurrency"`
 	}
 
 	// Options for fetching external documents over HTTP
@@ -50,6 +90,37 @@ type (
 
 		// Allow insecure connections (http)
 		AllowInsecure bool `json:"allowInsecure,omitempty" jsonschema:"title=Allow Insecure Connections"`
+
+		// Client to make requests with (e.g. for a custom transport or authentication). A copy is used
+		// so redirects can be re-validated against the allowed hosts
+		Client *http.Client `json:"-"`
+
+		// Headers to send with every request (e.g. "Authorization")
+		Headers map[string]string `json:"headers,omitempty" jsonschema:"title=Headers"`
+
+		// Timeout for each request (Defaults to 30 seconds unless the given client has a timeout)
+		Timeout time.Duration `json:"timeout,omitempty" jsonschema:"title=Timeout"`
+
+		// Maximum size of a document in bytes (Defaults to 10MiB)
+		MaxResponseSize int64 `json:"maxResponseSize,omitempty" jsonschema:"title=Max Response Size"`
+
+		// Maximum number of documents to fetch over HTTP for a schema (If zero, there is no limit). Documents are
+		// counted in the order they are queued so the same documents exceed it even when fetched concurrently
+		MaxDocuments int `json:"maxDocuments,omitempty" jsonschema:"title=Max Documents"`
+
+		// Refuse to connect to loopback, private and link-local addresses (checked after DNS resolution
+		// and for every redirect). Requires the client to use an *http.Transport and disables proxies
+		BlockPrivateNetworks bool `json:"blockPrivateNetworks,omitempty" jsonschema:"title=Block Private Networks"`
+
+		// Directory to cache fetched documents in keyed by their URL. Cached documents are revalidated with their
+		// "ETag" / "Last-Modified" once they are older than CacheTTL (If empty, documents are not cached)
+		CacheDir string `json:"cacheDir,omitempty" jsonschema:"title=Cache Directory"`
+
+		// How long cached documents are used without being revalidated (If zero, they are always revalidated)
+		CacheTTL time.Duration `json:"cacheTtl,omitempty" jsonschema:"title=Cache TTL"`
+
+		// Only serve documents from the cache without making any requests (Requires CacheDir)
+		Offline bool `json:"offline,omitempty" jsonschema:"title=Offline"`
 	}
 
 	// Options for fetching external documents from the file system
@@ -91,9 +162,16 @@ type (
 
 		// Document resolver for resolving external document references during parsing
 		DocumentResolver *documentResolver
+
+		// Name of the property or definition currently being parsed (Used for semantic inference)
+		PropertyName string
 	}
 
 	schemaNode struct {
+		// Annotations
+		Title       *string `json:"title,omitempty"`
+		Description *string `json:"description,omitempty"`
+
 		// Shared Properties
 		Type   *multipleType `json:"type,omitempty"`
 		Length *int          `json:"length,omitempty"` // Shared by String and Array
@@ -112,6 +190,10 @@ type (
 		MinLength *int    `json:"minLength,omitempty"`
 		MaxLength *int    `json:"maxLength,omitempty"`
 
+		// Bounds for the "date-time", "date" and "time" formats
+		FormatMinimum *string `json:"formatMinimum,omitempty"`
+		FormatMaximum *string `json:"formatMaximum,omitempty"`
+
 		// Number Properties
 		Minimum          *float64 `json:"minimum,omitempty"`
 		Maximum          *float64 `json:"maximum,omitempty"`
@@ -162,6 +244,9 @@ type (
 		// Unsupported
 		DependentSchemas map[string]schemaNode `json:"dependentSchemas,omitempty"`
 
+		// Extensions
+		XChaff *chaffHints `json:"x-chaff,omitempty"`
+
 		// Internal functionality
 		// Used to keep track of ifs from allOf statements that have been merged into this node (or factored into said node)
 		mergedIf []ifStatement
@@ -173,6 +258,9 @@ type (
 
 		// Internal map used to keep track of constraints that need to be applied to this node during parsing
 		constraints *constraintCollection
+
+		// Keys of "properties" in the order they are declared in the schema
+		propertyOrder []string
 	}
 )
 
@@ -219,6 +307,33 @@ func ParseSchemaFile(path string, opts *ParserOptions) (RootGenerator, error) {
 	return ParseSchema(data, opts)
 }
 
+// Parses a Json Schema file at the given path within an fs.FS (e.g. an embed.FS). Relative "$ref"s are resolved
+// within the fs.FS as well without touching the OS file system. If there is an error reading the file or
+// parsing the schema, an error will be returned
+func ParseSchemaFS(fsys fs.FS, path string, opts *ParserOptions) (RootGenerator, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return RootGenerator{
+			Generator: nullGenerator{},
+		}, err
+	}
+
+	// Work on a copy so the caller's options can be reused with other fs.FSs
+	fsOpts := *opts
+	if fsOpts.RelativeTo == "" {
+		fsOpts.RelativeTo = fsScheme + ":///" + path
+	}
+
+	// Fetchers given for the scheme explicitly are left alone
+	fetchers := map[string]DocumentFetcher{fsScheme: NewFSDocumentFetcher(fsys)}
+	for scheme, fetcher := range opts.DocumentFetchOptions.Fetchers {
+		fetchers[scheme] = fetcher
+	}
+
+	fsOpts.DocumentFetchOptions.Fetchers = fetchers
+	return ParseSchema(data, &fsOpts)
+}
+
 // Parses a Json Schema file at the given path with default options. If there is an error reading the file or
 // parsing the schema, an error will be returned
 func ParseSchemaFileWithDefaults(path string) (RootGenerator, error) {
@@ -235,26 +350,46 @@ func ParseSchemaStringWithDefaults(schema string) (RootGenerator, error) {
 }
 
 // Parses a Json Schema byte array. If there is an error parsing the schema, an error will be returned.
+// Schemas (and the documents they reference) may also be written in YAML or in JSON with comments
 func ParseSchema(schema []byte, opts *ParserOptions) (RootGenerator, error) {
-	var node schemaNode
-	err := json.Unmarshal(schema, &node)
 	defaultGenerator := RootGenerator{
 		Generator: nullGenerator{},
 	}
+
+	if opts.ValidateSchema {
+		if err := validateSchemaSource(getRelativeTo(*opts), schema); err != nil {
+			return defaultGenerator, err
+		}
+	}
+
+	// The original source is kept for diagnostics so their line numbers match it
+	data, err := decodeSchemaSource(getRelativeTo(*opts), schema)
+	if err != nil {
+		return defaultGenerator, err
+	}
+
+	var node schemaNode
+	err = json.Unmarshal(data, &node)
 	if err != nil {
 		return defaultGenerator, err
 	}
 
 	optsWithDefault := withDefaultParseOptions(*opts)
+	if err := validateSemanticRules(optsWithDefault.SemanticRules); err != nil {
+		return defaultGenerator, err
+	}
+
 	documentResolver, err := newDocumentResolver(optsWithDefault, &node)
 	if err != nil {
 		return defaultGenerator, err
 	}
 
+	documentResolver.sources[documentResolver.GetDocumentIdCurrentlyBeingParsed()] = schema
+
 	refHandler := newReferenceHandler(documentResolver)
 	errorCollection := newErrorCollection(refHandler, documentResolver)
 
-	schemaManager, err := newSchemaManager(documentResolver, schema)
+	schemaManager, err := newSchemaManager(documentResolver, data)
 	if err != nil {
 		return defaultGenerator, err
 	}
@@ -274,10 +409,17 @@ func ParseSchema(schema []byte, opts *ParserOptions) (RootGenerator, error) {
 	for metadata.DocumentResolver.HasMoreDocumentsToParse() {
 		_, err := metadata.DocumentResolver.ParseNextDocument(metadata)
 		if err != nil {
-			metadata.Errors.AddErrorWithSubpath("document_parse_error", err)
+			metadata.Errors.AddErrorWithSubpath("/document_parse_error", newDiagnosticError(DiagnosticSeverityError, DiagnosticCodeDocumentError, err))
 		}
 	}
 
+	metadata.ReferenceHandler.ParseUnvisitedTargets(metadata)
+	metadata.Errors.addSchemaValidationErrors(documentResolver.schemaValidationErrors)
+
+	if strictErr := checkStrictKeywords(metadata); strictErr != nil && err == nil {
+		err = strictErr
+	}
+
 	return generator, err
 }
 
@@ -301,6 +443,7 @@ func parseNode(node schemaNode, metadata *parserMetadata) (Generator, error) {
 
 	refHandler := metadata.ReferenceHandler
 
+	node.XChaff = parseHints(node, metadata)
 	gen, err := parseSchemaNode(node, metadata)
 
 	if err != nil {
@@ -320,7 +463,7 @@ func parseNode(node schemaNode, metadata *parserMetadata) (Generator, error) {
 	// Wrap in a constrained generator if there are constraints to apply
 	// to a given node
 	if node.constraints != nil {
-		return constrainedGenerator{
+		gen, err = constrainedGenerator{
 			internalGenerator: gen,
 			constraints: []constraint{
 				node.constraints.Compile(),
@@ -328,6 +471,21 @@ func parseNode(node schemaNode, metadata *parserMetadata) (Generator, error) {
 		}, nil
 	}
 
+	// Apply any option overrides given through "x-chaff" to the whole subtree
+	if node.XChaff != nil && node.XChaff.Options != nil {
+		gen = hintedGenerator{
+			internalGenerator: gen,
+			options:           *node.XChaff.Options,
+		}
+	}
+
+	// Keep track of where the generator came from for introspection
+	gen = locatedGenerator{
+		internalGenerator: gen,
+		Document:          metadata.DocumentResolver.GetDocumentIdCurrentlyBeingParsed(),
+		SchemaPath:        refHandler.CurrentPath,
+	}
+
 	return gen, err
 
 }
@@ -424,7 +582,18 @@ func withDefaultParseOptions(opts ParserOptions) ParserOptions {
 		RegexPatternPropertyOptions: opts.RegexPatternPropertyOptions,
 		DocumentFetchOptions:        opts.DocumentFetchOptions,
 		RelativeTo:                  opts.RelativeTo,
+		Documents:                   opts.Documents,
 		MaxParseDepth:               util.GetInt(opts.MaxParseDepth, defaultMaxParseDepth),
+		InferStringSemantics:        opts.InferStringSemantics,
+		SemanticRules:               opts.SemanticRules,
+		Strict:                      opts.Strict,
+		StrictFailOnUnknownKeywords: opts.StrictFailOnUnknownKeywords,
+		StrictAllowedKeywords:       opts.StrictAllowedKeywords,
+		ValidateSchema:              opts.ValidateSchema,
+	}
+
+	if len(parseOpts.SemanticRules) == 0 {
+		parseOpts.SemanticRules = DefaultSemanticRules()
 	}
 
 	defaultRegexOpts := &regen.GeneratorArgs{
@@ -457,7 +626,8 @@ func inferType(node schemaNode) string {
 	hasStringProps := node.Pattern != nil ||
 		node.Format != nil ||
 		node.MinLength != nil ||
-		node.MaxLength != nil
+		node.MaxLength != nil ||
+		(node.XChaff != nil && (node.XChaff.Faker != nil || node.XChaff.Template != nil))
 
 	if hasStringProps {
 		return typeString
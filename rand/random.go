@@ -45,9 +45,12 @@ func (sr *RandUtil) StringChoice(stringSlice *[]string) string {
 
 func (sr *RandUtil) StringChoiceMultiple(stringSlice *[]string, numChoices int) []string {
 	// Pick NumChoices random choices from the string slice without duplicates
-	choices := funk.Shuffle(*stringSlice).([]string)
+	choices := make([]string, numChoices)
+	for i, index := range sr.Rand.Perm(len(*stringSlice))[:numChoices] {
+		choices[i] = (*stringSlice)[index]
+	}
 
-	return choices[:numChoices]
+	return choices
 }
 
 // @todo - Reimplement to use std rand source
@@ -55,6 +58,42 @@ func (sr *RandUtil) Shuffle(in []interface{}) []interface{} {
 	return funk.Shuffle(in).([]interface{})
 }
 
+// Picks an index from the given weights with a probability proportional to its weight.
+// Returns -1 if no weight is greater than 0
+func (sr *RandUtil) WeightedIndex(weights []float64) int {
+	total := 0.0
+	for _, weight := range weights {
+		if weight > 0 {
+			total += weight
+		}
+	}
+
+	if total <= 0 {
+		return -1
+	}
+
+	target := sr.Rand.Float64() * total
+	for i, weight := range weights {
+		if weight <= 0 {
+			continue
+		}
+
+		target -= weight
+		if target < 0 {
+			return i
+		}
+	}
+
+	// Guard against floating point rounding leaving a remainder
+	for i := len(weights) - 1; i >= 0; i-- {
+		if weights[i] > 0 {
+			return i
+		}
+	}
+
+	return -1
+}
+
 // Int functions
 func (sr *RandUtil) RandomInt(min int, max int) int {
 	// In the the case that min == max, return min
@@ -30,7 +30,7 @@ func parseReference(node schemaNode, metadata *parserMetadata) (Generator, error
 	}
 
 	if strings.Contains(*node.Ref, "/allOf/") {
-		return nil, fmt.Errorf("references to things within allOf are not supported: %s", *node.Ref)
+		return nil, newDiagnosticError(DiagnosticSeverityUnsupported, DiagnosticCodeUnsupportedKeyword, fmt.Errorf("references to things within allOf are not supported: %s", *node.Ref))
 	}
 
 	documentId, ref, err := metadata.DocumentResolver.HandleDeferredReferenceResolution(*node.Ref, metadata)
@@ -38,6 +38,8 @@ func parseReference(node schemaNode, metadata *parserMetadata) (Generator, error
 		return nil, fmt.Errorf("failed to handle deferred reference resolution for ref '%s': %w", *node.Ref, err)
 	}
 
+	metadata.ReferenceHandler.AddTarget(documentId, ref)
+
 	return referenceGenerator{
 		Document:         documentId,
 		ReferenceStr:     ref,
@@ -70,9 +72,21 @@ func (g referenceGenerator) Generate(opts *GeneratorOptions) interface{} {
 	refResolver.PushRefResolution(g.Document, g.ReferenceStr)
 	defer refResolver.PopRefResolution()
 
+	if opts.trace != nil {
+		traceReference(opts, g, reference)
+	}
+
 	return reference.Generator.Generate(opts)
 }
 
 func (g referenceGenerator) String() string {
 	return fmt.Sprintf("ReferenceGenerator{document: %s, path: %s}", g.Document, g.ReferenceStr)
 }
+
+func (g referenceGenerator) describe(node *GeneratorNode) {
+	node.Kind = GeneratorKindReference
+	node.Reference = &GeneratorReference{
+		Document: g.Document,
+		Path:     g.ReferenceStr,
+	}
+}
@@ -2,6 +2,7 @@ package chaff
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/thoas/go-funk"
@@ -23,6 +24,9 @@ type (
 		CurrentPath      string
 		References       map[string]map[string]reference
 		Errors           map[string]map[string]error
+
+		// Paths "$ref"s point to by document. Used to parse targets that are not reached while parsing their documents
+		Targets map[string]map[string]bool
 	}
 
 	// This struct used to track a stack of resolved references
@@ -37,6 +41,7 @@ func newReferenceHandler(documentResolver *documentResolver) *referenceHandler {
 		CurrentPath:      "#",
 		References:       make(map[string]map[string]reference),
 		Errors:           make(map[string]map[string]error),
+		Targets:          make(map[string]map[string]bool),
 		documentResolver: documentResolver,
 	}
 }
@@ -100,6 +105,63 @@ func (h *referenceHandler) AddIdReference(path string, node schemaNode, generato
 	}
 }
 
+func (h *referenceHandler) AddTarget(documentId string, path string) {
+	if _, exists := h.Targets[documentId]; !exists {
+		h.Targets[documentId] = make(map[string]bool)
+	}
+
+	h.Targets[documentId][path] = true
+}
+
+// Parses the targets of references that were not reached while parsing their documents
+// (e.g. definitions nested in "$defs" or "not" sub-schemas) so they can be looked up during generation
+func (h *referenceHandler) ParseUnvisitedTargets(metadata *parserMetadata) {
+	resolver := h.documentResolver
+	attempted := map[string]bool{}
+	for {
+		unvisited := []string{}
+		for documentId, paths := range h.Targets {
+			for path := range paths {
+				if _, ok := h.Lookup(documentId, path); !ok && !attempted[documentId+"|"+path] {
+					unvisited = append(unvisited, documentId+"|"+path)
+				}
+			}
+		}
+
+		if len(unvisited) == 0 {
+			return
+		}
+
+		sort.Strings(unvisited)
+		for _, target := range unvisited {
+			attempted[target] = true
+			parts := strings.SplitN(target, "|", 2)
+			documentId, path := parts[0], parts[1]
+
+			document, ok := resolver.documents[documentId]
+			if !ok {
+				continue
+			}
+
+			node, err := resolveSubReferencePath(document, path, "")
+			if err != nil {
+				continue
+			}
+
+			parsingDocumentId, resolvingDocumentId, currentPath := resolver.documentCurrentlyBeingParsedId, resolver.documentCurrentlyBeingResolvedId, h.CurrentPath
+			resolver.documentCurrentlyBeingParsedId = documentId
+			resolver.SetDocumentBeingResolved(documentId)
+			h.CurrentPath = "#"
+
+			// Tracked as its own reference so references back to the target are detected as cycles
+			h.ParseNodeInScope(strings.TrimPrefix(path, "#"), *node, metadata, schemaNode{Ref: &path})
+
+			resolver.documentCurrentlyBeingParsedId, h.CurrentPath = parsingDocumentId, currentPath
+			resolver.SetDocumentBeingResolved(resolvingDocumentId)
+		}
+	}
+}
+
 func (h *referenceHandler) HandleError(err error, metadata *parserMetadata) {
 	documentId := h.documentResolver.GetDocumentIdCurrentlyBeingParsed()
 	h.Errors[h.CurrentPath][documentId] = err
@@ -60,7 +60,9 @@ func parseDefinitions(path string, metadata *parserMetadata, definitions map[str
 		// during merge, preventing infinite recursion when the definition
 		// references itself.
 		defPath := fmt.Sprintf("#/%s/%s", path, key)
-		generator, _ := ref.ParseNodeInScope(refPath, value, metadata, schemaNode{Ref: &defPath})
+		generator, _ := parseNodeWithPropertyName(key, metadata, func() (Generator, error) {
+			return ref.ParseNodeInScope(refPath, value, metadata, schemaNode{Ref: &defPath})
+		})
 
 		generators[key] = generator
 	}
@@ -71,7 +73,7 @@ func parseDefinitions(path string, metadata *parserMetadata, definitions map[str
 // Generates values based on the passed options
 func (g RootGenerator) Generate(opts *GeneratorOptions) interface{} {
 	opts = withGeneratorOptionsDefaults(*opts)
-	return g.Generator.Generate(opts)
+	return generateWithOverrides(opts, g.Generator)
 }
 
 func (g RootGenerator) GenerateWithDefaults() interface{} {
@@ -92,3 +94,7 @@ func (g RootGenerator) String() string {
 
 	return fmt.Sprintf("RootGenerator{Generator: %s Definitions: %s}", g.Generator, formattedString)
 }
+
+func (g RootGenerator) describe(node *GeneratorNode) {
+	*node = *g.Tree()
+}
@@ -0,0 +1,119 @@
+package chaff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/ryanolee/go-chaff/internal/util"
+)
+
+type (
+	// Maps property names, titles or descriptions onto a named string provider.
+	// Rules are checked in order against the property name, then the title, then the description
+	// of a string schema. Text is normalized before matching so "createdAt", "created_at" and
+	// "Created At" are all matched as "created at".
+	// Example:
+	//
+	//	chaff.SemanticRule{Match: regexp.MustCompile(`\biban\b`), Provider: "word"}
+	SemanticRule struct {
+		// Pattern to match normalized text against
+		Match *regexp.Regexp
+
+		// Name of the string provider to use (The same names supported by the "faker" x-chaff hint)
+		Provider string
+	}
+)
+
+// Returns the semantic rules used when ParserOptions.InferStringSemantics is enabled
+// and no custom rules are given. The returned slice can be extended and passed back through ParserOptions.SemanticRules.
+func DefaultSemanticRules() []SemanticRule {
+	return []SemanticRule{
+		{Match: regexp.MustCompile(`\be ?mails?( address(es)?)?\b`), Provider: "email"},
+		{Match: regexp.MustCompile(`\bip ?v6\b`), Provider: "ipv6"},
+		{Match: regexp.MustCompile(`\b(ip|ip ?v4)( address)?\b`), Provider: "ipv4"},
+		{Match: regexp.MustCompile(`\b(first ?name|given ?name|forename)\b`), Provider: "firstName"},
+		{Match: regexp.MustCompile(`\b(last ?name|family ?name|surname)\b`), Provider: "lastName"},
+		{Match: regexp.MustCompile(`\b(user ?name|login|nickname)\b`), Provider: "username"},
+		{Match: regexp.MustCompile(`^(full |display |person |contact )?name$`), Provider: "name"},
+		{Match: regexp.MustCompile(`\b(phone|telephone|mobile|cell|fax)( number)?\b`), Provider: "phone"},
+		{Match: regexp.MustCompile(`\b(zip|zip ?code|post ?code|postal ?code)\b`), Provider: "postalCode"},
+		{Match: regexp.MustCompile(`\b(city|town)\b`), Provider: "city"},
+		{Match: regexp.MustCompile(`\bcountry\b`), Provider: "country"},
+		{Match: regexp.MustCompile(`\b(street|address( line ?\d?)?)\b`), Provider: "streetAddress"},
+		{Match: regexp.MustCompile(`\b(url|uri|website|homepage|link|href)\b`), Provider: "url"},
+		{Match: regexp.MustCompile(`\b(domain|host ?name)\b`), Provider: "domainName"},
+		{Match: regexp.MustCompile(`\b(uuid|guid)\b`), Provider: "uuid"},
+		{Match: regexp.MustCompile(`\b(created|updated|modified|deleted|published|expires|expired) at\b|\b(timestamp|date ?time)\b`), Provider: "dateTime"},
+		{Match: regexp.MustCompile(`\b(created|updated|modified|deleted|published|expires|expired) on\b|\b(date|birthday|birth ?date|dob)\b`), Provider: "date"},
+		{Match: regexp.MustCompile(`\btime\b`), Provider: "time"},
+	}
+}
+
+var semanticWordBoundaryRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Checks that every rule refers to a known string provider
+func validateSemanticRules(rules []SemanticRule) error {
+	for i, rule := range rules {
+		if rule.Match == nil {
+			return fmt.Errorf("semantic rule %d has no match pattern", i)
+		}
+
+		if _, ok := stringProviders[rule.Provider]; !ok {
+			return fmt.Errorf("semantic rule %d refers to unknown provider '%s' (known providers: %s)", i, rule.Provider, strings.Join(getStringProviderNames(), ", "))
+		}
+	}
+
+	return nil
+}
+
+// Picks a string provider for the given node based on the property name currently being parsed,
+// the "title" and the "description" of the node. Returns an empty string if nothing matches.
+func inferStringProvider(propertyName string, node schemaNode, rules []SemanticRule) string {
+	sources := []string{
+		propertyName,
+		util.GetZeroIfNil(node.Title, ""),
+		util.GetZeroIfNil(node.Description, ""),
+	}
+
+	for _, source := range sources {
+		text := normalizeSemanticText(source)
+		if text == "" {
+			continue
+		}
+
+		for _, rule := range rules {
+			if rule.Match.MatchString(text) {
+				return rule.Provider
+			}
+		}
+	}
+
+	return ""
+}
+
+// Runs the given parse function with the name of the property being parsed made available for semantic inference
+func parseNodeWithPropertyName(name string, metadata *parserMetadata, parse func() (Generator, error)) (Generator, error) {
+	previousName := metadata.PropertyName
+	metadata.PropertyName = name
+	defer func() { metadata.PropertyName = previousName }()
+
+	return parse()
+}
+
+// Splits camel case and separators into lower case space separated words
+// e.g "createdAt" -> "created at", "EMAIL_ADDRESS" -> "email address"
+func normalizeSemanticText(text string) string {
+	var sb strings.Builder
+	runes := []rune(text)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+			sb.WriteRune(' ')
+		}
+
+		sb.WriteRune(unicode.ToLower(r))
+	}
+
+	return strings.TrimSpace(semanticWordBoundaryRegex.ReplaceAllString(sb.String(), " "))
+}
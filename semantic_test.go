@@ -0,0 +1,101 @@
+package chaff_test
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanolee/go-chaff"
+	test "github.com/ryanolee/go-chaff/internal/test_utils"
+)
+
+func getSemanticChaffConfig() *chaff.ParserOptions {
+	return &chaff.ParserOptions{
+		InferStringSemantics: true,
+	}
+}
+
+func TestSemantic(t *testing.T) {
+	t.Parallel()
+	test.TestJsonSchemaDirWithConfig(t, "test_data/semantic", 100, getSemanticChaffConfig(), nil)
+}
+
+func TestSemanticInferredValues(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaString(`{
+		"type": "object",
+		"properties": {
+			"userEmail": {"type": "string"},
+			"created_at": {"type": "string"},
+			"name": {"type": "string", "x-chaff": {"faker": "uuid"}},
+			"summary": {"type": "string", "title": "Email Address"}
+		},
+		"required": ["userEmail", "created_at", "name", "summary"]
+	}`, getSemanticChaffConfig())
+
+	if err != nil || generator.Metadata.Errors.HasErrors() {
+		t.Fatalf("Failed to parse schema: %v %v", err, generator.Metadata.Errors.CollectErrors())
+	}
+
+	uuidRegex := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	for i := 0; i < 100; i++ {
+		value := generator.GenerateWithDefaults().(map[string]interface{})
+		if _, err := mail.ParseAddress(value["userEmail"].(string)); err != nil {
+			t.Fatalf("Expected an email address for 'userEmail', got %q", value["userEmail"])
+		}
+
+		if _, err := mail.ParseAddress(value["summary"].(string)); err != nil {
+			t.Fatalf("Expected an email address for a string titled 'Email Address', got %q", value["summary"])
+		}
+
+		if _, err := time.Parse(time.RFC3339, value["created_at"].(string)); err != nil {
+			t.Fatalf("Expected a date time for 'created_at', got %q", value["created_at"])
+		}
+
+		if !uuidRegex.MatchString(value["name"].(string)) {
+			t.Fatalf("Expected x-chaff hints to take precedence over inferred values, got %q", value["name"])
+		}
+	}
+}
+
+func TestSemanticDisabledByDefault(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{"type": "object", "properties": {"email": {"type": "string"}}, "required": ["email"]}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	if strings.Contains(generator.String(), "email]") {
+		t.Fatalf("Expected no provider to be inferred without InferStringSemantics, got %s", generator)
+	}
+}
+
+func TestSemanticCustomRules(t *testing.T) {
+	t.Parallel()
+	rules := append(chaff.DefaultSemanticRules(), chaff.SemanticRule{
+		Match:    regexp.MustCompile(`\bcorrelation id\b`),
+		Provider: "uuid",
+	})
+
+	generator, err := chaff.ParseSchemaString(`{"type": "string", "title": "Correlation ID"}`, &chaff.ParserOptions{
+		InferStringSemantics: true,
+		SemanticRules:        rules,
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	value := generator.GenerateWithDefaults().(string)
+	if !regexp.MustCompile(`^[0-9a-f-]{36}$`).MatchString(value) {
+		t.Fatalf("Expected custom rule to produce a uuid, got %q", value)
+	}
+
+	_, err = chaff.ParseSchemaString(`{"type": "string"}`, &chaff.ParserOptions{
+		SemanticRules: []chaff.SemanticRule{{Match: regexp.MustCompile(`x`), Provider: "unknown"}},
+	})
+	if err == nil {
+		t.Fatalf("Expected an error for a semantic rule with an unknown provider")
+	}
+}
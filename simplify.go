@@ -0,0 +1,308 @@
+package chaff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/thoas/go-funk"
+)
+
+type (
+	// Rewrites schema nodes into their flattened equivalents
+	schemaSimplifier struct {
+		metadata *parserMetadata
+
+		// If any "$ref" had to be kept in the output (e.g. because it is cyclic)
+		keptReferences bool
+	}
+)
+
+// Simplifies a schema into an equivalent schema without "allOf" or "$ref" so what a composed schema
+// really means can be reviewed. "allOf" sub-schemas are merged into their parent (intersecting bounds,
+// enums and types, merging properties, "prefixItems", "not" and "if" etc.) and "$ref"s are inlined,
+// including those pointing to external documents if fetching them is enabled in the given options.
+// Cyclic "$ref"s can not be inlined so they are kept along with the definitions they point to.
+// Returns the simplified schema (nil if the schema could not be parsed at all) and the diagnostics
+// reported while parsing and simplifying it.
+func Simplify(schema []byte, opts *ParserOptions) ([]byte, Diagnostics) {
+	if opts == nil {
+		opts = &ParserOptions{}
+	}
+
+	generator, err := ParseSchema(schema, opts)
+	if generator.Metadata == nil {
+		var validationErr *SchemaValidationError
+		if errors.As(err, &validationErr) {
+			return nil, validationErr.Diagnostics
+		}
+
+		return nil, Diagnostics{newDiagnostic(getRelativeTo(*opts), "#", err)}
+	}
+
+	// Simplification starts back at the root document once all referenced documents have been parsed
+	metadata := generator.Metadata
+	metadata.DocumentResolver.documentCurrentlyBeingParsedId = getRelativeTo(metadata.ParserOptions)
+	metadata.DocumentResolver.SetDocumentBeingResolved("")
+	metadata.ReferenceHandler.CurrentPath = "#"
+
+	simplifier := &schemaSimplifier{metadata: metadata}
+	root := metadata.RootNode
+	simplified := simplifier.simplify(root, []string{})
+
+	// Definitions are only needed for the references that could not be inlined
+	if simplifier.keptReferences {
+		simplified.Defs = simplifier.simplifyDefinitions("$defs", root.Defs)
+		simplified.Definitions = simplifier.simplifyDefinitions("definitions", root.Definitions)
+	}
+
+	output, err := json.MarshalIndent(simplified, "", "    ")
+	if err != nil {
+		return nil, append(generator.Diagnostics(), newDiagnostic(getRelativeTo(*opts), "#", err))
+	}
+
+	return output, generator.Diagnostics()
+}
+
+// Simplifies the schema in the file at the given path resolving relative "$ref"s against it (See Simplify)
+func SimplifyFile(path string, opts *ParserOptions) ([]byte, Diagnostics) {
+	if opts == nil {
+		opts = &ParserOptions{}
+	}
+
+	path, err := getRealPath(path)
+	if err != nil {
+		return nil, Diagnostics{newDiagnostic("file://"+path, "#", err)}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, Diagnostics{newDiagnostic("file://"+path, "#", err)}
+	}
+
+	if opts.RelativeTo == "" {
+		opts.RelativeTo = "file://" + path
+	}
+
+	return Simplify(data, opts)
+}
+
+func (s *schemaSimplifier) simplify(node schemaNode, stack []string) schemaNode {
+	for node.Ref != nil || node.AllOf != nil {
+		if node.Ref != nil {
+			documentId, path, err := s.metadata.DocumentResolver.ResolveDocumentIdAndPath(*node.Ref)
+			if err != nil {
+				s.metadata.Errors.AddErrorWithSubpath("/$ref", fmt.Errorf("failed to resolve ref [%s]: %w", *node.Ref, err))
+				s.keptReferences = true
+				break
+			}
+
+			// Inlining a reference to a node that is already being inlined would never terminate
+			if funk.ContainsString(stack, documentId+path) {
+				node.Ref = s.canonicalRef(documentId, path)
+				s.keptReferences = true
+				break
+			}
+
+			stack = append(stack, documentId+path)
+			siblings := node
+			siblings.Ref = nil
+			merged, err := mergeSchemaNodes(s.metadata, schemaNode{Ref: node.Ref}, siblings)
+			if err != nil {
+				s.metadata.Errors.AddErrorWithSubpath("/$ref", fmt.Errorf("failed to inline ref [%s]: %w", *node.Ref, err))
+				s.keptReferences = true
+				break
+			}
+
+			// The base URI of the inlined node is that of the node it is inlined into
+			merged.Id = nil
+			node = merged
+			continue
+		}
+
+		nodes := append([]schemaNode{}, *node.AllOf...)
+		node.AllOf = nil
+		nodes = append(nodes, node)
+
+		for _, subSchema := range nodes {
+			if subSchema.Ref == nil {
+				continue
+			}
+
+			if documentId, path, err := s.metadata.DocumentResolver.ResolveDocumentIdAndPath(*subSchema.Ref); err == nil {
+				stack = append(stack, documentId+path)
+			}
+		}
+
+		if err := checkAllOfTypes(nodes); err != nil {
+			s.metadata.Errors.AddErrorWithSubpath("/allOf", newContradictoryAllOfError(err))
+		}
+
+		merged, err := mergeSchemaNodes(s.metadata, nodes...)
+		if err != nil {
+			s.metadata.Errors.AddErrorWithSubpath("/allOf", newContradictoryAllOfError(err))
+		}
+
+		node = merged
+	}
+
+	return s.simplifyChildren(node, stack)
+}
+
+// Simplifies the sub-schemas of an already flattened node
+func (s *schemaSimplifier) simplifyChildren(node schemaNode, stack []string) schemaNode {
+	node.Defs = nil
+	node.Definitions = nil
+
+	node.Properties = s.simplifyMap("/properties", node.Properties, stack)
+	node.PatternProperties = s.simplifyMap("/patternProperties", node.PatternProperties, stack)
+	if node.DependentSchemas != nil {
+		node.DependentSchemas = *s.simplifyMap("/dependentSchemas", &node.DependentSchemas, stack)
+	}
+
+	node.AdditionalProperties = s.simplifyNodeOrFalse("/additionalProperties", node.AdditionalProperties, stack)
+	node.AdditionalItems = s.simplifyNodeOrFalse("/additionalItems", node.AdditionalItems, stack)
+	node.UnevaluatedItems = s.simplifyNodeOrFalse("/unevaluatedItems", node.UnevaluatedItems, stack)
+
+	if node.Items != nil {
+		items := *node.Items
+		items.Node = s.simplifyPtr("/items", items.Node, stack)
+		items.Nodes = s.simplifySlice("/items", items.Nodes, stack)
+		node.Items = &items
+	}
+
+	node.PrefixItems = s.simplifySlice("/prefixItems", node.PrefixItems, stack)
+	node.Contains = s.simplifyPtr("/contains", node.Contains, stack)
+	node.AnyOf = s.simplifySlice("/anyOf", node.AnyOf, stack)
+	node.OneOf = s.simplifySlice("/oneOf", node.OneOf, stack)
+
+	// not(A) and not(B) is equivalent to not(anyOf(A, B))
+	nots := []schemaNode{}
+	for _, not := range append([]*schemaNode{node.Not}, node.mergedNot...) {
+		if not != nil {
+			nots = append(nots, *not)
+		}
+	}
+
+	node.Not = nil
+	node.mergedNot = nil
+	if len(nots) == 1 {
+		node.Not = s.simplifyPtr("/not", &nots[0], stack)
+	} else if len(nots) > 1 {
+		node.Not = &schemaNode{AnyOf: s.simplifySlice("/not/anyOf", &nots, stack)}
+	}
+
+	// Multiple conditions can only be expressed side by side through "allOf"
+	ifs := node.mergedIf
+	if node.If != nil {
+		ifs = append([]ifStatement{newIfStatement(node, "")}, ifs...)
+	}
+
+	node.If, node.Then, node.Else = nil, nil, nil
+	node.mergedIf = nil
+	if len(ifs) == 1 {
+		node.If = s.simplifyPtr("/if", ifs[0].If, stack)
+		node.Then = s.simplifyPtr("/then", ifs[0].Then, stack)
+		node.Else = s.simplifyPtr("/else", ifs[0].Else, stack)
+	} else if len(ifs) > 1 {
+		conditions := []schemaNode{}
+		for i, statement := range ifs {
+			path := fmt.Sprintf("/allOf/%d", i)
+			conditions = append(conditions, schemaNode{
+				If:   s.simplifyPtr(path+"/if", statement.If, stack),
+				Then: s.simplifyPtr(path+"/then", statement.Then, stack),
+				Else: s.simplifyPtr(path+"/else", statement.Else, stack),
+			})
+		}
+
+		node.AllOf = &conditions
+	}
+
+	return node
+}
+
+// Simplifies the definitions of the root document. Each definition starts out as being inlined
+// so references back to itself are kept rather than inlined once more
+func (s *schemaSimplifier) simplifyDefinitions(keyword string, definitions *map[string]schemaNode) *map[string]schemaNode {
+	if definitions == nil {
+		return nil
+	}
+
+	rootDocumentId := s.metadata.DocumentResolver.GetDocumentIdCurrentlyBeingParsed()
+	simplified := make(map[string]schemaNode, len(*definitions))
+	for key, definition := range *definitions {
+		path := fmt.Sprintf("/%s/%s", keyword, escapeJsonPointerSegment(key))
+		simplified[key] = s.simplifyAt(path, definition, []string{rootDocumentId + "#" + path})
+	}
+
+	return &simplified
+}
+
+// Returns the given reference relative to the root document if it points into it
+func (s *schemaSimplifier) canonicalRef(documentId string, path string) *string {
+	ref := documentId + path
+	if documentId == s.metadata.DocumentResolver.GetDocumentIdCurrentlyBeingParsed() {
+		ref = path
+	}
+
+	return &ref
+}
+
+func (s *schemaSimplifier) simplifyAt(path string, node schemaNode, stack []string) schemaNode {
+	s.metadata.ReferenceHandler.PushToPath(path)
+	defer s.metadata.ReferenceHandler.PopFromPath(path)
+
+	// Each branch gets its own copy of the stack so siblings do not see each other's references
+	return s.simplify(node, append([]string{}, stack...))
+}
+
+func (s *schemaSimplifier) simplifyPtr(path string, node *schemaNode, stack []string) *schemaNode {
+	if node == nil {
+		return nil
+	}
+
+	simplified := s.simplifyAt(path, *node, stack)
+	return &simplified
+}
+
+func (s *schemaSimplifier) simplifySlice(path string, nodes *[]schemaNode, stack []string) *[]schemaNode {
+	if nodes == nil {
+		return nil
+	}
+
+	simplified := make([]schemaNode, len(*nodes))
+	for i, node := range *nodes {
+		simplified[i] = s.simplifyAt(fmt.Sprintf("%s/%d", path, i), node, stack)
+	}
+
+	return &simplified
+}
+
+func (s *schemaSimplifier) simplifyMap(path string, nodes *map[string]schemaNode, stack []string) *map[string]schemaNode {
+	if nodes == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(*nodes))
+	for key := range *nodes {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	simplified := make(map[string]schemaNode, len(*nodes))
+	for _, key := range keys {
+		simplified[key] = s.simplifyAt(fmt.Sprintf("%s/%s", path, escapeJsonPointerSegment(key)), (*nodes)[key], stack)
+	}
+
+	return &simplified
+}
+
+func (s *schemaSimplifier) simplifyNodeOrFalse(path string, node *schemaNodeOrFalse, stack []string) *schemaNodeOrFalse {
+	if node == nil || node.Schema == nil {
+		return node
+	}
+
+	return &schemaNodeOrFalse{Schema: s.simplifyPtr(path, node.Schema, stack)}
+}
@@ -0,0 +1,156 @@
+package chaff_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+func simplifySchema(t *testing.T, schema string) map[string]interface{} {
+	simplified, diagnostics := chaff.Simplify([]byte(schema), nil)
+	if simplified == nil {
+		t.Fatalf("Failed to simplify schema: %v", diagnostics)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(simplified, &result); err != nil {
+		t.Fatalf("Failed to unmarshal simplified schema: %s", err)
+	}
+
+	return result
+}
+
+func assertSimplified(t *testing.T, expected string, actual map[string]interface{}) {
+	var expectedSchema map[string]interface{}
+	if err := json.Unmarshal([]byte(expected), &expectedSchema); err != nil {
+		t.Fatalf("Failed to unmarshal expected schema: %s", err)
+	}
+
+	if !reflect.DeepEqual(expectedSchema, actual) {
+		actualJson, _ := json.Marshal(actual)
+		t.Errorf("Expected simplified schema %s, got %s", expected, actualJson)
+	}
+}
+
+func TestSimplifyAllOf(t *testing.T) {
+	t.Parallel()
+	simplified := simplifySchema(t, `{
+		"$defs": {"positive": {"type": "integer", "minimum": 1}},
+		"allOf": [
+			{"$ref": "#/$defs/positive"},
+			{"maximum": 100, "multipleOf": 4},
+			{"maximum": 50, "multipleOf": 6}
+		]
+	}`)
+
+	assertSimplified(t, `{"type": "integer", "minimum": 1, "maximum": 50, "multipleOf": 12}`, simplified)
+}
+
+func TestSimplifyObjects(t *testing.T) {
+	t.Parallel()
+	simplified := simplifySchema(t, `{
+		"type": "object",
+		"allOf": [
+			{"properties": {"kind": {"enum": ["a", "b", "c"]}}, "required": ["kind"], "not": {"required": ["x"]}},
+			{"properties": {"kind": {"enum": ["b", "c", "d"]}, "name": {"type": "string"}}, "not": {"required": ["y"]}},
+			{"if": {"properties": {"kind": {"const": "b"}}}, "then": {"required": ["name"]}},
+			{"if": {"properties": {"kind": {"const": "c"}}}, "then": {"maxProperties": 1}}
+		]
+	}`)
+
+	assertSimplified(t, `{
+		"type": "object",
+		"properties": {"kind": {"enum": ["b", "c"]}, "name": {"type": "string"}},
+		"required": ["kind"],
+		"not": {"anyOf": [{"required": ["x"]}, {"required": ["y"]}]},
+		"allOf": [
+			{"if": {"properties": {"kind": {"const": "b"}}}, "then": {"required": ["name"]}},
+			{"if": {"properties": {"kind": {"const": "c"}}}, "then": {"maxProperties": 1}}
+		]
+	}`, simplified)
+}
+
+func TestSimplifyInlinesReferences(t *testing.T) {
+	t.Parallel()
+	simplified := simplifySchema(t, `{
+		"$defs": {
+			"name": {"type": "string", "minLength": 1},
+			"person": {"type": "object", "properties": {"name": {"$ref": "#/$defs/name"}}}
+		},
+		"type": "array",
+		"items": {"$ref": "#/$defs/person", "required": ["name"]}
+	}`)
+
+	assertSimplified(t, `{
+		"type": "array",
+		"items": {"type": "object", "properties": {"name": {"type": "string", "minLength": 1}}, "required": ["name"]}
+	}`, simplified)
+}
+
+func TestSimplifyCyclicReferences(t *testing.T) {
+	t.Parallel()
+	simplified := simplifySchema(t, `{
+		"$defs": {
+			"node": {"type": "object", "properties": {"value": {"type": "integer"}, "next": {"$ref": "#/$defs/node"}}}
+		},
+		"$ref": "#/$defs/node"
+	}`)
+
+	assertSimplified(t, `{
+		"type": "object",
+		"properties": {"value": {"type": "integer"}, "next": {"$ref": "#/$defs/node"}},
+		"$defs": {
+			"node": {"type": "object", "properties": {"value": {"type": "integer"}, "next": {"$ref": "#/$defs/node"}}}
+		}
+	}`, simplified)
+}
+
+func TestSimplifyExternalDocuments(t *testing.T) {
+	t.Parallel()
+	simplified, diagnostics := chaff.SimplifyFile("test_data/document/file/cluster1_simple_refs/main.json", getDocumentChaffConfig())
+	if simplified == nil || len(diagnostics) != 0 {
+		t.Fatalf("Failed to simplify schema: %v", diagnostics)
+	}
+
+	if strings.Contains(string(simplified), "$ref") {
+		t.Errorf("Expected all references to be inlined, got %s", simplified)
+	}
+
+	var result struct {
+		Properties map[string]struct {
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"properties"`
+	}
+
+	if err := json.Unmarshal(simplified, &result); err != nil {
+		t.Fatalf("Failed to unmarshal simplified schema: %s", err)
+	}
+
+	if _, ok := result.Properties["shippingAddress"].Properties["zipCode"]; !ok {
+		t.Errorf("Expected the address document to be inlined, got %s", simplified)
+	}
+}
+
+func TestSimplifyDiagnostics(t *testing.T) {
+	t.Parallel()
+	simplified, diagnostics := chaff.Simplify([]byte(`{"allOf": [{"type": "string"}, {"type": "integer"}]}`), nil)
+	if simplified == nil {
+		t.Fatalf("Expected a simplified schema despite the contradiction")
+	}
+
+	found := false
+	for _, diagnostic := range diagnostics {
+		found = found || diagnostic.Code == chaff.DiagnosticCodeContradictoryAllOf
+	}
+
+	if !found {
+		t.Errorf("Expected the contradiction to be reported, got %v", diagnostics)
+	}
+
+	if simplified, diagnostics := chaff.Simplify([]byte(`{"type": `), nil); simplified != nil || len(diagnostics) != 1 {
+		t.Errorf("Expected invalid json to be reported, got %s %v", simplified, diagnostics)
+	}
+}
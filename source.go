@@ -0,0 +1,301 @@
+package chaff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Returns the JSON for the source of a schema document which may also be written in YAML or in JSON with
+// comments and trailing commas. YAML is detected by the ".yaml" / ".yml" extension of the document ID or
+// otherwise by the source being a YAML mapping rather than JSON. Sources that are neither are returned
+// (without any comments) for the JSON parser to report
+func decodeSchemaSource(documentId string, source []byte) ([]byte, error) {
+	if isYamlDocumentId(documentId) {
+		value, err := decodeYaml(source)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(value)
+	}
+
+	if json.Valid(source) {
+		return source, nil
+	}
+
+	stripped := stripJsonComments(source)
+	if json.Valid(stripped) {
+		return stripped, nil
+	}
+
+	if value, err := decodeYaml(source); err == nil {
+		if _, ok := value.(OrderedObject); ok {
+			return json.Marshal(value)
+		}
+	}
+
+	return stripped, nil
+}
+
+// Checks if the document ID has a YAML file extension
+func isYamlDocumentId(documentId string) bool {
+	documentPath := documentId
+	if parsedUrl, err := url.Parse(documentId); err == nil {
+		documentPath = parsedUrl.Path
+	}
+
+	extension := strings.ToLower(path.Ext(documentPath))
+	return extension == ".yaml" || extension == ".yml"
+}
+
+// Replaces comments and trailing commas in JSON with whitespace. Newlines are kept so line and column
+// numbers of everything else stay the same as in the source
+func stripJsonComments(source []byte) []byte {
+	stripped := make([]byte, len(source))
+	copy(stripped, source)
+
+	inString := false
+	for i := 0; i < len(stripped); i++ {
+		switch {
+		case inString:
+			if stripped[i] == '\\' {
+				i++
+			} else if stripped[i] == '"' {
+				inString = false
+			}
+		case stripped[i] == '"':
+			inString = true
+		case stripped[i] == '/' && i+1 < len(stripped) && stripped[i+1] == '/':
+			for ; i < len(stripped) && stripped[i] != '\n'; i++ {
+				stripped[i] = ' '
+			}
+		case stripped[i] == '/' && i+1 < len(stripped) && stripped[i+1] == '*':
+			end := bytes.Index(stripped[i+2:], []byte("*/"))
+			if end == -1 {
+				end = len(stripped)
+			} else {
+				end += i + 4
+			}
+
+			for ; i < end; i++ {
+				if stripped[i] != '\n' && stripped[i] != '\r' {
+					stripped[i] = ' '
+				}
+			}
+
+			i--
+		}
+	}
+
+	// Commas are only trailing once the comments between them and the closing bracket are gone
+	inString = false
+	for i := 0; i < len(stripped); i++ {
+		switch {
+		case inString:
+			if stripped[i] == '\\' {
+				i++
+			} else if stripped[i] == '"' {
+				inString = false
+			}
+		case stripped[i] == '"':
+			inString = true
+		case stripped[i] == ',':
+			next := i + 1
+			for next < len(stripped) && strings.IndexByte(" \t\r\n", stripped[next]) != -1 {
+				next++
+			}
+
+			if next < len(stripped) && (stripped[next] == '}' || stripped[next] == ']') {
+				stripped[i] = ' '
+			}
+		}
+	}
+
+	return stripped
+}
+
+// Decodes a YAML document into the values encoding/json would decode the same document into.
+// Mappings are decoded as OrderedObjects so the order of "properties" survives the conversion to JSON
+// unless the document uses merge keys ("<<") which only the generic decoding resolves
+func decodeYaml(source []byte) (interface{}, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(source, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse yaml: %w", err)
+	}
+
+	keepYamlLiterals(&document)
+
+	var value interface{}
+	if err := document.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to parse yaml: %w", err)
+	}
+
+	if len(document.Content) > 0 {
+		if ordered, ok := convertYamlNode(document.Content[0]); ok {
+			return ordered, nil
+		}
+	}
+
+	return convertYamlValue(value), nil
+}
+
+// Keeps scalars without a JSON equivalent as the text they were written as so decoding them only gives
+// JSON compatible values (e.g. "2024-01-01" rather than a time.Time or ".inf" rather than an infinite float)
+func keepYamlLiterals(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		switch node.ShortTag() {
+		case "!!timestamp", "!!binary":
+			node.Tag = "!!str"
+		case "!!float":
+			var number float64
+			if err := node.Decode(&number); err == nil && (math.IsInf(number, 0) || math.IsNaN(number)) {
+				node.Tag = "!!str"
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		keepYamlLiterals(child)
+	}
+}
+
+// Converts a YAML node to the values decodeYaml returns. Returns false if the node can not be
+// converted keeping its order (e.g. it uses merge keys)
+func convertYamlNode(node *yaml.Node) (interface{}, bool) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		if node.Alias == nil {
+			return nil, false
+		}
+
+		return convertYamlNode(node.Alias)
+	case yaml.MappingNode:
+		object := OrderedObject{Keys: []string{}, Values: map[string]interface{}{}}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			if keyNode.Tag == "!!merge" {
+				return nil, false
+			}
+
+			var key interface{}
+			if err := keyNode.Decode(&key); err != nil {
+				return nil, false
+			}
+
+			value, ok := convertYamlNode(node.Content[i+1])
+			if !ok {
+				return nil, false
+			}
+
+			name := fmt.Sprint(key)
+			if _, exists := object.Values[name]; !exists {
+				object.Keys = append(object.Keys, name)
+			}
+
+			object.Values[name] = value
+		}
+
+		return object, true
+	case yaml.SequenceNode:
+		items := make([]interface{}, 0, len(node.Content))
+		for _, child := range node.Content {
+			item, ok := convertYamlNode(child)
+			if !ok {
+				return nil, false
+			}
+
+			items = append(items, item)
+		}
+
+		return items, true
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return nil, false
+		}
+
+		return convertYamlValue(value), true
+	}
+}
+
+// Converts maps with non string keys (e.g. status codes in OpenAPI "responses") to JSON objects
+func convertYamlValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			value[key] = convertYamlValue(child)
+		}
+
+		return value
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for key, child := range value {
+			object[fmt.Sprint(key)] = convertYamlValue(child)
+		}
+
+		return object
+	case []interface{}:
+		for i, child := range value {
+			value[i] = convertYamlValue(child)
+		}
+
+		return value
+	default:
+		return value
+	}
+}
+
+// Finds the line and column of the value at the given path within a YAML document resolving to the
+// deepest value that exists like locateJsonPointer. Returns false if the source is not a YAML mapping
+func locateYamlPointer(source []byte, segments []string) (int, int, bool) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(source, &document); err != nil || len(document.Content) == 0 {
+		return 0, 0, false
+	}
+
+	node := document.Content[0]
+	if node.Kind != yaml.MappingNode {
+		return 0, 0, false
+	}
+
+	for _, segment := range segments {
+		child := getYamlChild(node, segment)
+		if child == nil {
+			break
+		}
+
+		node = child
+	}
+
+	return node.Line, node.Column, true
+}
+
+// Returns the value under the given key or index of a YAML mapping or sequence (nil if there is none)
+func getYamlChild(node *yaml.Node, segment string) *yaml.Node {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment {
+				return node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if index, err := strconv.Atoi(segment); err == nil && index >= 0 && index < len(node.Content) {
+			return node.Content[index]
+		}
+	}
+
+	return nil
+}
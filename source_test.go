@@ -0,0 +1,118 @@
+package chaff_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+func TestYamlAndJsoncDocuments(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaFile("test_data/document/file/cluster7_yaml/main.yaml", getDocumentChaffConfig())
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	result, ok := generator.GenerateWithDefaults().(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected an object to be generated")
+	}
+
+	customer, _ := result["customer"].(map[string]interface{})
+	address, _ := result["shippingAddress"].(map[string]interface{})
+	if customer["name"] != "Ada Lovelace" || address["zipCode"] != "12345" {
+		t.Errorf("Expected the YAML and JSONC documents to be used, got %v", result)
+	}
+
+	// Diagnostics point into the YAML source rather than the JSON it was converted to
+	var found *chaff.Diagnostic
+	for _, diagnostic := range generator.Diagnostics() {
+		if strings.HasSuffix(diagnostic.Document, "customer.yml") {
+			found = &diagnostic
+		}
+	}
+
+	if found == nil || found.Line != 8 || found.Column != 5 {
+		t.Errorf("Expected the invalid pattern to be reported at 8:5 of customer.yml, got %v", generator.Diagnostics())
+	}
+}
+
+func TestYamlSchemaSniffing(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults("type: object\nproperties:\n  200:\n    const: ok\nrequired: ['200']\n")
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	result, ok := generator.GenerateWithDefaults().(map[string]interface{})
+	if !ok || result["200"] != "ok" {
+		t.Errorf("Expected the schema to be read as YAML, got %v", generator.GenerateWithDefaults())
+	}
+
+	if _, err := chaff.ParseSchemaStringWithDefaults(`{"type": `); err == nil {
+		t.Errorf("Expected invalid JSON to still fail to parse")
+	}
+}
+
+func TestYamlSchemaTimestamps(t *testing.T) {
+	t.Parallel()
+	// Merge keys are decoded without keeping the order of the mapping
+	for _, schema := range []string{
+		"type: object\nproperties:\n  date:\n    const: 2024-01-01\n  time:\n    const: !!timestamp 2024-01-01T10:00:00Z\nrequired: [date, time]\n",
+		"base: &base\n  type: object\n<<: *base\nproperties:\n  date:\n    const: 2024-01-01\n  time:\n    const: !!timestamp 2024-01-01T10:00:00Z\nrequired: [date, time]\n",
+	} {
+		fsys := fstest.MapFS{"schema.yaml": {Data: []byte(schema)}}
+		generator, err := chaff.ParseSchemaFS(fsys, "schema.yaml", &chaff.ParserOptions{})
+		if err != nil {
+			t.Fatalf("Failed to parse schema: %s", err)
+		}
+
+		result, ok := generator.GenerateWithDefaults().(map[string]interface{})
+		if !ok || result["date"] != "2024-01-01" || result["time"] != "2024-01-01T10:00:00Z" {
+			t.Errorf("Expected timestamps to keep the text they were written as, got %v", generator.GenerateWithDefaults())
+		}
+	}
+}
+
+func TestJsoncSchema(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{
+		// The "//" in strings are not comments
+		"type": "object",
+		"properties": {
+			"url": {"const": "https://example.com/*"}, /* trailing comma */
+		},
+		"required": ["url",],
+	}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	result, ok := generator.GenerateWithDefaults().(map[string]interface{})
+	if !ok || result["url"] != "https://example.com/*" {
+		t.Errorf("Expected the commented schema to be parsed, got %v", generator.GenerateWithDefaults())
+	}
+}
+
+func TestJsoncDiagnostics(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{
+		/*
+		 * Comments keep the line numbers of the source
+		 */
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "pattern": "(["},
+		},
+	}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	diagnostics := generator.Diagnostics()
+	if len(diagnostics) != 1 || diagnostics[0].Line != 7 || diagnostics[0].Column != 12 {
+		t.Errorf("Expected the invalid pattern to be reported at 7:12, got %v", diagnostics)
+	}
+}
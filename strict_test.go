@@ -0,0 +1,115 @@
+package chaff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+const strictTestSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"$comment": "Only annotations and extensions are allowed",
+	"title": "Strict",
+	"type": "object",
+	"x-internal": true,
+	"propertyNames": {"pattern": "^[a-z]+$"},
+	"properties": {
+		"name": {"type": "string", "minLenght": 3, "description": "Name"},
+		"tag": {"$anchor": "tag", "type": "string", "x-vocab-unit": "cm", "vocabUnit": "cm"}
+	}
+}`
+
+func TestStrictRecordsIgnoredKeywords(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaString(strictTestSchema, &chaff.ParserOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("Expected strict mode not to fail parsing by default, got %s", err)
+	}
+
+	expected := map[string]chaff.DiagnosticCode{
+		"#/propertyNames":             chaff.DiagnosticCodeUnsupportedKeyword,
+		"#/properties/name/minLenght": chaff.DiagnosticCodeUnknownKeyword,
+		"#/properties/tag/$anchor":    chaff.DiagnosticCodeUnsupportedKeyword,
+		"#/properties/tag/vocabUnit":  chaff.DiagnosticCodeUnknownKeyword,
+	}
+
+	diagnostics := generator.Diagnostics()
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %d: %v", len(expected), len(diagnostics), diagnostics)
+	}
+
+	for _, diagnostic := range diagnostics {
+		if code, ok := expected[diagnostic.Pointer]; !ok || code != diagnostic.Code {
+			t.Errorf("Unexpected diagnostic %s", diagnostic)
+		}
+
+		if diagnostic.Line == 0 {
+			t.Errorf("Expected diagnostic %s to have a position", diagnostic)
+		}
+	}
+}
+
+func TestStrictFailOnUnknownKeywords(t *testing.T) {
+	t.Parallel()
+	_, err := chaff.ParseSchemaString(strictTestSchema, &chaff.ParserOptions{
+		Strict:                      true,
+		StrictFailOnUnknownKeywords: true,
+	})
+
+	if err == nil {
+		t.Fatalf("Expected strict mode to fail parsing")
+	}
+
+	if !strings.Contains(err.Error(), "#/properties/name/minLenght") {
+		t.Errorf("Expected the error to list the unrecognised keywords, got %s", err)
+	}
+}
+
+func TestStrictAllowedKeywords(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaString(`{"type": "string", "vocabUnit": "cm"}`, &chaff.ParserOptions{
+		Strict:                      true,
+		StrictFailOnUnknownKeywords: true,
+		StrictAllowedKeywords:       []string{"vocabUnit"},
+	})
+
+	if err != nil {
+		t.Fatalf("Expected allowed keywords not to fail parsing, got %s", err)
+	}
+
+	if diagnostics := generator.Diagnostics(); len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", diagnostics)
+	}
+}
+
+func TestStrictDisabled(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(strictTestSchema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	if diagnostics := generator.Diagnostics(); len(diagnostics) != 0 {
+		t.Errorf("Expected ignored keywords not to be recorded without strict mode, got %v", diagnostics)
+	}
+}
+
+func TestStrictExternalDocument(t *testing.T) {
+	t.Parallel()
+	opts := getDocumentDiagnosticsConfig()
+	opts.Strict = true
+	generator, err := chaff.ParseSchemaString(`{"$ref": "test_data/diagnostics/strict.json"}`, opts)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	diagnostics := generator.Diagnostics()
+	if len(diagnostics) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %d: %v", len(diagnostics), diagnostics)
+	}
+
+	if !strings.HasSuffix(diagnostics[0].Document, "test_data/diagnostics/strict.json") || diagnostics[0].Pointer != "#/unevaluatedProperties" {
+		t.Errorf("Expected the diagnostic to point into the external document, got %s", diagnostics[0])
+	}
+}
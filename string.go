@@ -3,9 +3,8 @@ package chaff
 import (
 	"fmt"
 	"strings"
-	"time"
+	"unicode/utf8"
 
-	"github.com/go-faker/faker/v4"
 	"github.com/ryanolee/go-chaff/internal/regen"
 	"github.com/ryanolee/go-chaff/internal/util"
 )
@@ -17,11 +16,21 @@ type (
 		PatternGenerator regen.Generator
 		MinLength        int
 		MaxLength        int
+
+		// Bounds given through "formatMinimum" / "formatMaximum" for date and time formats
+		TimeBounds timeBounds
+
+		// Named provider or template given through "x-chaff" hints
+		Provider string
+		Template string
 	}
 )
 
 type stringFormat string
 
+// Number of attempts made to get a provider value within the length bounds of a string
+const maximumProviderAttempts = 10
+
 const (
 	// Time
 	formatDateTime stringFormat = "date-time" // RFC3339
@@ -79,7 +88,7 @@ func parseString(node schemaNode, metadata *parserMetadata) (Generator, error) {
 	}
 
 	if minLength > maxLength && maxLength != 0 {
-		return nullGenerator{}, fmt.Errorf("minLength cannot be greater than maxLength")
+		return nullGenerator{}, newDiagnosticError(DiagnosticSeverityError, DiagnosticCodeUnsatisfiable, fmt.Errorf("minLength cannot be greater than maxLength"))
 	}
 
 	hasPatternBasedBuilder := node.Pattern != nil || node.Format != nil
@@ -95,6 +104,23 @@ func parseString(node schemaNode, metadata *parserMetadata) (Generator, error) {
 		MaxLength: maxLength,
 	}
 
+	timeBounds, err := parseTimeBounds(generator.Format, node, metadata)
+	if err != nil {
+		return nullGenerator{}, err
+	}
+
+	generator.TimeBounds = timeBounds
+
+	if node.XChaff != nil {
+		generator.Provider = util.GetZeroIfNil(node.XChaff.Faker, "")
+		generator.Template = util.GetZeroIfNil(node.XChaff.Template, "")
+	}
+
+	// Explicit hints always take precedence over anything inferred
+	if metadata.ParserOptions.InferStringSemantics && !hasPatternBasedBuilder && generator.Provider == "" && generator.Template == "" {
+		generator.Provider = inferStringProvider(metadata.PropertyName, node, metadata.ParserOptions.SemanticRules)
+	}
+
 	if node.Pattern != nil {
 		regenGenerator, err := newRegexGenerator(*node.Pattern, metadata.ParserOptions.RegexStringOptions)
 		if err != nil {
@@ -109,6 +135,10 @@ func parseString(node schemaNode, metadata *parserMetadata) (Generator, error) {
 
 func (g stringGenerator) Generate(opts *GeneratorOptions) interface{} {
 	opts.overallComplexity++
+	if g.TimeBounds.isSet() {
+		return generateTimeFormat(g.Format, opts, g.TimeBounds)
+	}
+
 	if g.Format != "" {
 		return generateFormat(g.Format, opts)
 	}
@@ -117,52 +147,116 @@ func (g stringGenerator) Generate(opts *GeneratorOptions) interface{} {
 		return g.PatternGenerator.Generate()
 	}
 
-	// Build a string with a single sentence in it
-	var sb strings.Builder
-	sb.Write([]byte(faker.Sentence()))
+	minLength := util.GetInt(g.MinLength, opts.DefaultStringMinLength)
+	maxLength := util.GetInt(g.MaxLength, opts.DefaultStringMaxLength)
+	// Bounds given by the schema always win over the defaults
+	if maxLength != 0 && minLength > maxLength {
+		if g.MaxLength != 0 {
+			minLength = maxLength
+		} else {
+			maxLength = 0
+		}
+	}
+
+	if g.Template != "" {
+		return fitStringLength(renderTemplate(g.Template, opts), minLength, maxLength, opts)
+	}
+
+	if provider, ok := stringProviders[g.Provider]; ok {
+		return fitStringLength(generateWithinLength(provider, opts, minLength, maxLength), minLength, maxLength, opts)
+	}
+
+	return fitStringLength(opts.DataProvider.Sentence(opts.Rand), minLength, maxLength, opts)
+}
+
+// Makes a few attempts to get a value from the provider that already fits the length bounds
+// so that it does not need to be padded or truncated
+func generateWithinLength(provider stringProvider, opts *GeneratorOptions, minLength int, maxLength int) string {
+	value := provider(opts)
+	for i := 0; i < maximumProviderAttempts && !isWithinLength(value, minLength, maxLength); i++ {
+		value = provider(opts)
+	}
+
+	return value
+}
+
+// Lengths are measured in code points as per the JSON Schema specification
+func isWithinLength(value string, minLength int, maxLength int) bool {
+	length := utf8.RuneCountInString(value)
+	return length >= minLength && (maxLength == 0 || length <= maxLength)
+}
+
+// Pads the given string with sentences until it reaches the minimum length
+// and truncates it should it exceed the maximum length (0 for no maximum)
+func fitStringLength(value string, minLength int, maxLength int, opts *GeneratorOptions) string {
+	runes := []rune(value)
 
 	// Keep on filling it until there is a full sentence
-	for sb.Len() < g.MinLength {
-		sb.Write([]byte(faker.Sentence()))
+	for len(runes) < minLength {
+		sentence := []rune(opts.DataProvider.Sentence(opts.Rand))
+		if len(sentence) == 0 {
+			// Data providers may give empty sentences (e.g. with an empty word list) so pad with a fixed character instead
+			runes = append(runes, []rune(strings.Repeat("x", minLength-len(runes)))...)
+			break
+		}
+
+		runes = append(runes, sentence...)
 	}
 
 	// Truncate it if it get's too long
-	if g.MaxLength != 0 && sb.Len() > g.MaxLength {
-		return sb.String()[:g.MaxLength]
+	if maxLength != 0 && len(runes) > maxLength {
+		return string(runes[:maxLength])
 	}
 
-	return sb.String()
+	return string(runes)
 }
 
 func (g stringGenerator) String() string {
+	if g.Provider != "" || g.Template != "" {
+		return fmt.Sprintf("StringGenerator[%s, %s, %s%s]", g.Format, g.Pattern, g.Provider, g.Template)
+	}
+
 	return fmt.Sprintf("StringGenerator[%s, %s]", g.Format, g.Pattern)
 }
 
 func generateFormat(format stringFormat, opts *GeneratorOptions) string {
+	data := opts.DataProvider
 	switch format {
-	case formatDateTime:
-		return time.Unix(faker.UnixTime(), 0).Format(time.RFC3339)
-	case formatTime:
-		return fmt.Sprintf("%s+00:00", time.Unix(faker.UnixTime(), 0).Format(time.TimeOnly))
-	case formatDate:
-		return time.Unix(faker.UnixTime(), 0).Format(time.DateOnly)
+	case formatDateTime, formatTime, formatDate:
+		return generateTimeFormat(format, opts, timeBounds{})
 	case formatDuration:
 		return fmt.Sprintf("P%dD", opts.Rand.RandomInt(0, 90))
-	case formatEmail, formatIdnEmail:
-		return faker.Email()
-	case formatHostname, formatIdnHostname:
-		return faker.DomainName()
+	case formatEmail:
+		return data.Email(opts.Rand)
+	case formatIdnEmail:
+		return generateIdnEmail(opts)
+	case formatHostname:
+		return data.DomainName(opts.Rand)
+	case formatIdnHostname:
+		return generateIdnHostname(opts)
 	case formatIpv4:
-		return faker.IPv4()
+		return data.IPv4(opts.Rand)
 	case formatIpv6:
-		return faker.IPv6()
+		return data.IPv6(opts.Rand)
 	case formatUUID:
-		return faker.UUIDHyphenated()
-	case formatURI, formatURIReference, formatIRI, formatIRIReference:
-		return faker.URL()
+		return data.UUID(opts.Rand)
+	case formatURI, formatURIReference:
+		return data.URL(opts.Rand)
+	case formatIRI, formatIRIReference:
+		return generateIri(opts)
 	case formatUriTemplate, formatJSONPointer, formatRelativeJSONPointer, formatRegex:
 		return fmt.Sprintf("Known but unsupported format: %s", format)
 	default:
 		return fmt.Sprintf("Unsupported Format: %s", format)
 	}
 }
+
+func (g stringGenerator) describe(node *GeneratorNode) {
+	node.Kind = GeneratorKindString
+	node.Format = string(g.Format)
+	node.Pattern = g.Pattern
+	node.Provider = g.Provider
+	node.Template = g.Template
+	node.Bounds.MinLength = intBound(g.MinLength)
+	node.Bounds.MaxLength = intBound(g.MaxLength)
+}
@@ -0,0 +1,245 @@
+package chaff
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ryanolee/go-chaff/internal/util"
+)
+
+type (
+	// Window of time that "date-time", "date" and "time" formats are generated within.
+	// Either give explicit instants or durations relative to GeneratorOptions.Now.
+	// Example:
+	//
+	//	// The last 90 days
+	//	chaff.TimeWindow{Past: 90 * 24 * time.Hour}
+	//
+	//	// Between two instants
+	//	chaff.TimeWindow{Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	TimeWindow struct {
+		// Earliest instant to generate (Takes precedence over Past)
+		Start time.Time `json:"start,omitempty" jsonschema:"title=Start"`
+
+		// Latest instant to generate (Takes precedence over Future)
+		End time.Time `json:"end,omitempty" jsonschema:"title=End"`
+
+		// How far before "now" values can be generated
+		Past time.Duration `json:"past,omitempty" jsonschema:"title=Past"`
+
+		// How far after "now" values can be generated
+		Future time.Duration `json:"future,omitempty" jsonschema:"title=Future"`
+	}
+
+	// Bounds given by the "formatMinimum" and "formatMaximum" keywords
+	timeBounds struct {
+		Minimum *time.Time
+		Maximum *time.Time
+	}
+)
+
+// Window used for schema bounds that are only bounded on one side
+const defaultBoundedTimeWindow = 365 * 24 * time.Hour
+
+// Date "formatMinimum" / "formatMaximum" bounds of the "time" format are anchored to
+// so that they can be compared as instants
+var timeFormatReferenceDate = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func (w TimeWindow) isZero() bool {
+	return w.Start.IsZero() && w.End.IsZero() && w.Past == 0 && w.Future == 0
+}
+
+// Parses the "formatMinimum" and "formatMaximum" keywords of a date, date-time or time string. They are
+// reported as ignored for any other format
+func parseTimeBounds(format stringFormat, node schemaNode, metadata *parserMetadata) (timeBounds, error) {
+	bounds := timeBounds{}
+	if node.FormatMinimum == nil && node.FormatMaximum == nil {
+		return bounds, nil
+	}
+
+	// Bounds on other formats are ignored so the format is still generated as it is without them
+	if format != formatDateTime && format != formatDate && format != formatTime {
+		if node.FormatMinimum != nil {
+			warnField(metadata, "formatMinimum", fmt.Errorf("formatMinimum is only supported for the date-time, date and time formats (given: %s)", format))
+		}
+
+		if node.FormatMaximum != nil {
+			warnField(metadata, "formatMaximum", fmt.Errorf("formatMaximum is only supported for the date-time, date and time formats (given: %s)", format))
+		}
+
+		return bounds, nil
+	}
+
+	for _, bound := range []struct {
+		name   string
+		value  *string
+		target **time.Time
+	}{
+		{"formatMinimum", node.FormatMinimum, &bounds.Minimum},
+		{"formatMaximum", node.FormatMaximum, &bounds.Maximum},
+	} {
+		if bound.value == nil {
+			continue
+		}
+
+		parsed, err := parseTimeFormat(format, *bound.value)
+		if err != nil {
+			return bounds, fmt.Errorf("invalid %s for format %s: %w", bound.name, format, err)
+		}
+
+		*bound.target = &parsed
+	}
+
+	if bounds.Minimum != nil && bounds.Maximum != nil && bounds.Minimum.After(*bounds.Maximum) {
+		return bounds, fmt.Errorf("formatMinimum (%s) must be before or equal to formatMaximum (%s)", *node.FormatMinimum, *node.FormatMaximum)
+	}
+
+	return bounds, nil
+}
+
+func parseTimeFormat(format stringFormat, value string) (time.Time, error) {
+	switch format {
+	case formatDate:
+		return time.Parse(time.DateOnly, value)
+	case formatTime:
+		parsed, err := time.Parse("15:04:05.999999999Z07:00", strings.ToUpper(value))
+		if err != nil {
+			return parsed, err
+		}
+
+		return time.Date(timeFormatReferenceDate.Year(), timeFormatReferenceDate.Month(), timeFormatReferenceDate.Day(),
+			parsed.Hour(), parsed.Minute(), parsed.Second(), parsed.Nanosecond(), parsed.Location()), nil
+	default:
+		return time.Parse(time.RFC3339Nano, strings.ToUpper(value))
+	}
+}
+
+// Generates a "date-time", "date" or "time" string within the configured time window and schema bounds
+func generateTimeFormat(format stringFormat, opts *GeneratorOptions, bounds timeBounds) string {
+	location := getTimeLocation(opts)
+
+	// Dates and times bounded by the schema are kept in the zone of their bounds
+	// as moving them into another zone could move them outside of the bounds
+	if format != formatDateTime && bounds.isSet() {
+		location = util.GetPtr(bounds.Minimum, bounds.Maximum).Location()
+	}
+
+	timestamp := generateTimestamp(format, opts, bounds, location).In(location)
+	fraction := ""
+	if opts.FractionalSecondDigits > 0 {
+		fraction = "." + strings.Repeat("0", opts.FractionalSecondDigits)
+	}
+
+	switch format {
+	case formatDate:
+		return timestamp.Format(time.DateOnly)
+	case formatTime:
+		return timestamp.Format("15:04:05" + fraction + "-07:00")
+	default:
+		return timestamp.Format("2006-01-02T15:04:05" + fraction + "Z07:00")
+	}
+}
+
+func (b timeBounds) isSet() bool {
+	return b.Minimum != nil || b.Maximum != nil
+}
+
+// Picks an instant within the time window of the generator options narrowed by the schema bounds.
+// Falls back to the data provider if neither a window, a clock nor any bounds are given.
+func generateTimestamp(format stringFormat, opts *GeneratorOptions, bounds timeBounds, location *time.Location) time.Time {
+	if opts.TimeWindow.isZero() && opts.Now == nil && !bounds.isSet() {
+		return opts.DataProvider.Timestamp(opts.Rand)
+	}
+
+	// "time" bounds are anchored to a reference date so generate on that date
+	start, end := getTimeWindow(opts)
+	if format == formatTime && bounds.isSet() {
+		year, month, day := timeFormatReferenceDate.Date()
+		start = time.Date(year, month, day, 0, 0, 0, 0, location)
+		end = start.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	start, end = clampTimeWindow(start, end, bounds)
+	return randomTimeBetween(opts, start, end)
+}
+
+// Resolves the configured time window into concrete instants
+func getTimeWindow(opts *GeneratorOptions) (time.Time, time.Time) {
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+
+	window := opts.TimeWindow
+	start, end := time.Unix(0, 0), now
+	if window.Past != 0 || window.Future != 0 {
+		start, end = now.Add(-window.Past), now.Add(window.Future)
+	}
+
+	if !window.Start.IsZero() {
+		start = window.Start
+	}
+
+	if !window.End.IsZero() {
+		end = window.End
+	}
+
+	return start, end
+}
+
+// Narrows the window to the schema bounds. Should the window not overlap the bounds, the bounds win.
+func clampTimeWindow(start time.Time, end time.Time, bounds timeBounds) (time.Time, time.Time) {
+	if bounds.Minimum != nil && start.Before(*bounds.Minimum) {
+		start = *bounds.Minimum
+	}
+
+	if bounds.Maximum != nil && end.After(*bounds.Maximum) {
+		end = *bounds.Maximum
+	}
+
+	if !start.After(end) {
+		return start, end
+	}
+
+	switch {
+	case bounds.Minimum != nil && bounds.Maximum != nil:
+		return *bounds.Minimum, *bounds.Maximum
+	case bounds.Minimum != nil:
+		return *bounds.Minimum, bounds.Minimum.Add(defaultBoundedTimeWindow)
+	case bounds.Maximum != nil:
+		return bounds.Maximum.Add(-defaultBoundedTimeWindow), *bounds.Maximum
+	default:
+		return end, start
+	}
+}
+
+// Picks a random instant between start and end (inclusive). Whole seconds are
+// generated unless fractional seconds are requested so that rendered values stay within the window
+func randomTimeBetween(opts *GeneratorOptions, start time.Time, end time.Time) time.Time {
+	unit := time.Second
+	if opts.FractionalSecondDigits > 0 {
+		unit = time.Nanosecond
+	}
+
+	first := start.Truncate(unit)
+	if first.Before(start) {
+		first = first.Add(unit)
+	}
+
+	steps := int64(end.Truncate(unit).Sub(first) / unit)
+	if steps <= 0 {
+		return first
+	}
+
+	return first.Add(time.Duration(opts.Rand.Rand.Int63n(steps+1)) * unit)
+}
+
+// Picks the location to render times in. Explicit time zones take precedence over the offsets of the locale
+func getTimeLocation(opts *GeneratorOptions) *time.Location {
+	if len(opts.TimeZones) > 0 {
+		return opts.TimeZones[opts.Rand.RandomInt(0, len(opts.TimeZones))]
+	}
+
+	return getLocaleLocation(opts)
+}
@@ -0,0 +1,209 @@
+package chaff_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanolee/go-chaff"
+	test "github.com/ryanolee/go-chaff/internal/test_utils"
+	"github.com/ryanolee/go-chaff/rand"
+)
+
+var testNow = time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+func TestTimeFormat(t *testing.T) {
+	t.Parallel()
+	test.TestJsonSchemaDirWithConfig(t, "test_data/time", 50, nil, func() *chaff.GeneratorOptions {
+		return &chaff.GeneratorOptions{
+			Now:                    func() time.Time { return testNow },
+			TimeWindow:             chaff.TimeWindow{Past: 90 * 24 * time.Hour},
+			TimeZones:              []*time.Location{time.UTC, time.FixedZone("", 5*60*60+30*60)},
+			FractionalSecondDigits: 3,
+		}
+	})
+}
+
+func TestTimeFormatWindow(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{"type": "string", "format": "date-time"}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name   string
+		opts   chaff.GeneratorOptions
+		start  time.Time
+		end    time.Time
+		suffix string
+	}{
+		{
+			name:  "Past",
+			opts:  chaff.GeneratorOptions{Now: func() time.Time { return testNow }, TimeWindow: chaff.TimeWindow{Past: 90 * 24 * time.Hour}},
+			start: testNow.Add(-90 * 24 * time.Hour),
+			end:   testNow,
+		},
+		{
+			name:  "Future",
+			opts:  chaff.GeneratorOptions{Now: func() time.Time { return testNow }, TimeWindow: chaff.TimeWindow{Future: time.Hour}},
+			start: testNow,
+			end:   testNow.Add(time.Hour),
+		},
+		{
+			name:   "BetweenInstants",
+			opts:   chaff.GeneratorOptions{TimeWindow: chaff.TimeWindow{Start: start, End: end}, TimeZones: []*time.Location{time.FixedZone("", 9*60*60)}},
+			start:  start,
+			end:    end,
+			suffix: "+09:00",
+		},
+		{
+			name:   "FractionalSeconds",
+			opts:   chaff.GeneratorOptions{TimeWindow: chaff.TimeWindow{Start: start, End: end}, FractionalSecondDigits: 6},
+			start:  start,
+			end:    end,
+			suffix: "Z",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				value := generator.Generate(&tc.opts).(string)
+				generated, err := time.Parse(time.RFC3339Nano, value)
+				if err != nil {
+					t.Fatalf("Failed to parse date-time %q: %s", value, err)
+				}
+
+				if generated.Before(tc.start) || generated.After(tc.end) {
+					t.Fatalf("Expected date-time between %s and %s, got %s", tc.start, tc.end, value)
+				}
+
+				if !strings.HasSuffix(value, tc.suffix) {
+					t.Fatalf("Expected date-time to end with %q, got %s", tc.suffix, value)
+				}
+
+				_, fraction, _ := strings.Cut(strings.TrimSuffix(value, "Z"), ".")
+				if len(fraction) != tc.opts.FractionalSecondDigits {
+					t.Fatalf("Expected %d fractional second digits, got %s", tc.opts.FractionalSecondDigits, value)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeFormatBounds(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaFileWithDefaults("test_data/time/time_bounds.json")
+	if err != nil || generator.Metadata.Errors.HasErrors() {
+		t.Fatalf("Failed to parse schema: %v %v", err, generator.Metadata.Errors.CollectErrors())
+	}
+
+	timeLayout := "15:04:05.999999999Z07:00"
+	cases := []struct {
+		key     string
+		layout  string
+		minimum string
+		maximum string
+	}{
+		{key: "testBoundedDateTime", layout: time.RFC3339Nano, minimum: "2024-01-01T00:00:00Z", maximum: "2024-01-31T23:59:59Z"},
+		{key: "testBoundedDate", layout: time.DateOnly, minimum: "2020-02-01", maximum: "2020-02-29"},
+		{key: "testBoundedTime", layout: timeLayout, minimum: "09:00:00+02:00", maximum: "17:30:00+02:00"},
+		{key: "testMinimumDate", layout: time.DateOnly, minimum: "2999-01-01", maximum: "3000-01-01"},
+		{key: "testMaximumDateTime", layout: time.RFC3339Nano, minimum: "1970-01-01T00:00:00Z", maximum: "1999-12-31T23:59:59Z"},
+	}
+
+	for i := 0; i < 50; i++ {
+		value := generator.Generate(&chaff.GeneratorOptions{
+			Now:                    func() time.Time { return testNow },
+			TimeZones:              []*time.Location{time.FixedZone("", -8*60*60)},
+			FractionalSecondDigits: 2,
+		}).(map[string]interface{})
+
+		for _, tc := range cases {
+			generated, err := time.Parse(tc.layout, value[tc.key].(string))
+			if err != nil {
+				t.Fatalf("Failed to parse %s %q: %s", tc.key, value[tc.key], err)
+			}
+
+			minimum, _ := time.Parse(tc.layout, tc.minimum)
+			maximum, _ := time.Parse(tc.layout, tc.maximum)
+			if generated.Before(minimum) || generated.After(maximum) {
+				t.Fatalf("Expected %s between %s and %s, got %s", tc.key, tc.minimum, tc.maximum, value[tc.key])
+			}
+		}
+
+		if !strings.HasSuffix(value["testBoundedTime"].(string), "+02:00") {
+			t.Fatalf("Expected bounded time to keep the offset of its bounds, got %s", value["testBoundedTime"])
+		}
+	}
+}
+
+func TestTimeFormatDeterministic(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaFileWithDefaults("test_data/time/time_bounds.json")
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	generate := func() interface{} {
+		return generator.Generate(&chaff.GeneratorOptions{
+			Rand:       rand.NewRandUtil(42),
+			Now:        func() time.Time { return testNow },
+			TimeWindow: chaff.TimeWindow{Past: 365 * 24 * time.Hour},
+		})
+	}
+
+	first, second := generate(), generate()
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("Expected the same values for the same seed and clock, got %v and %v", first, second)
+	}
+}
+
+func TestTimeFormatBoundsInvalid(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{
+		"type": "object",
+		"properties": {
+			"malformed": {"type": "string", "format": "date", "formatMinimum": "yesterday"},
+			"inverted": {"type": "string", "format": "date-time", "formatMinimum": "2024-02-01T00:00:00Z", "formatMaximum": "2024-01-01T00:00:00Z"},
+			"unsupported": {"type": "string", "format": "email", "formatMinimum": "a@example.com"}
+		},
+		"required": ["unsupported"]
+	}`)
+
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	errors := generator.Metadata.Errors.CollectErrors()
+	for _, expectedPath := range []string{"/properties/malformed", "/properties/inverted", "/properties/unsupported/formatMinimum"} {
+		found := false
+		for path := range errors {
+			if strings.HasSuffix(path, expectedPath) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			t.Errorf("Expected an error to be reported at %s, got %v", expectedPath, errors)
+		}
+	}
+
+	// Bounds on formats other than times are ignored rather than failing the node
+	for _, diagnostic := range generator.Diagnostics() {
+		if strings.HasSuffix(diagnostic.Pointer, "/properties/unsupported/formatMinimum") &&
+			(diagnostic.Severity != chaff.DiagnosticSeverityWarning || diagnostic.Code != chaff.DiagnosticCodeKeywordIgnored) {
+			t.Errorf("Expected a keyword_ignored warning for the unsupported bound, got %v", diagnostic)
+		}
+	}
+
+	result, ok := generator.GenerateWithDefaults().(map[string]interface{})
+	if email, _ := result["unsupported"].(string); !ok || !strings.Contains(email, "@") {
+		t.Errorf("Expected an email to still be generated, got %v", result)
+	}
+}
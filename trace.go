@@ -0,0 +1,362 @@
+package chaff
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Provenance of every value of a generated document keyed by the JSON pointer of the value
+	// (e.g. "/items/3/price"). The root value is keyed by an empty pointer.
+	Trace map[string]TraceEntry
+
+	// Describes the schema node that produced a generated value
+	TraceEntry struct {
+		// The document and JSON pointer of the schema node that produced the value.
+		// For values produced through a "$ref" this is the node the reference points to
+		Document   string `json:"document,omitempty"`
+		SchemaPath string `json:"schemaPath,omitempty"`
+
+		// Kind of the generator that produced the value
+		Kind GeneratorKind `json:"kind"`
+
+		// References followed to reach the schema node, outermost first
+		References []GeneratorReference `json:"references,omitempty"`
+
+		// "oneOf" / "anyOf" branches picked to produce the value, outermost first
+		Branches []TraceBranch `json:"branches,omitempty"`
+
+		// Number of attempts made to satisfy "if" / "then" / "else" conditions of the value
+		IfAttempts int `json:"ifAttempts,omitempty"`
+
+		// "then" / "else" branches the value satisfied
+		Conditions []TraceCondition `json:"conditions,omitempty"`
+
+		// Set when the constraints of the value could not be satisfied within the configured number of attempts
+		Unsatisfied bool `json:"unsatisfied,omitempty"`
+
+		// Set for values that were not generated from the schema but to fill an object
+		Filler TraceFiller `json:"filler,omitempty"`
+
+		// Set for values given through GeneratorOptions.Overrides
+		Overridden bool `json:"overridden,omitempty"`
+	}
+
+	// A branch of a "oneOf" or "anyOf" keyword picked during generation
+	TraceBranch struct {
+		// The keyword the branch belongs to
+		Keyword GeneratorKind `json:"keyword"`
+
+		// Document and JSON pointer of the schema node with the keyword
+		Document   string `json:"document,omitempty"`
+		SchemaPath string `json:"schemaPath,omitempty"`
+
+		// Index of the picked branch
+		Index int `json:"index"`
+	}
+
+	// The branch of an "if" keyword a value satisfied
+	TraceCondition struct {
+		// Document and JSON pointer of the schema node with the "if" keyword
+		Document   string `json:"document,omitempty"`
+		SchemaPath string `json:"schemaPath,omitempty"`
+
+		// Index of the "if" within the node (Nodes can have several through "allOf")
+		Index int `json:"index"`
+
+		// Either "then" or "else"
+		Branch string `json:"branch"`
+	}
+
+	// The reason a value was generated to fill an object
+	TraceFiller string
+
+	// Collects trace entries during generation
+	traceState struct {
+		entries map[string]*TraceEntry
+
+		// Pointers of the values currently being generated. Generators nested within one another
+		// for the same value (e.g. a "$ref" or a "oneOf" branch) refine the entry of the outermost generator
+		active map[string]bool
+	}
+
+	// Restores the trace entry of the current value before regenerating it so retries
+	// for constraints don't carry over what was recorded for discarded attempts
+	tracedRetryGenerator struct {
+		internalGenerator Generator
+		snapshot          TraceEntry
+	}
+)
+
+const (
+	// Value of a required property that has no schema (e.g. "required_foo_123")
+	TraceFillerRequired TraceFiller = "required"
+
+	// Value of an optional property named by "required" elsewhere that has no schema (e.g. "optional_foo_123")
+	TraceFillerOptional TraceFiller = "optional"
+
+	// Property generated to reach the default number of properties (e.g. "fallback_0")
+	TraceFillerFallback TraceFiller = "fallback"
+
+	// Property generated to satisfy "minProperties" (e.g. "min_filler_0")
+	TraceFillerMinProperties TraceFiller = "min_filler"
+)
+
+// Generates a value alongside a trace of the schema node that produced each part of it
+func (g RootGenerator) GenerateWithTrace(opts *GeneratorOptions) (interface{}, Trace) {
+	opts = withGeneratorOptionsDefaults(*opts)
+	opts.trace = &traceState{
+		entries: map[string]*TraceEntry{},
+		active:  map[string]bool{},
+	}
+
+	value := generateWithOverrides(opts, g.Generator)
+	return value, opts.trace.collect(value)
+}
+
+// Returns the pointers of the trace in lexical order
+func (t Trace) Pointers() []string {
+	pointers := make([]string, 0, len(t))
+	for pointer := range t {
+		pointers = append(pointers, pointer)
+	}
+
+	sort.Strings(pointers)
+	return pointers
+}
+
+// Returns the entry for the value currently being generated. A new entry is started unless the
+// value is already being generated further up the stack. The returned function must be called once the value is generated
+func (s *traceState) enter(path []string) (*TraceEntry, func()) {
+	pointer := formatJsonPointer(path)
+	if s.active[pointer] {
+		return s.entries[pointer], func() {}
+	}
+
+	entry := &TraceEntry{}
+	s.entries[pointer] = entry
+	s.active[pointer] = true
+
+	return entry, func() { delete(s.active, pointer) }
+}
+
+// Returns the entry for the value currently being generated (If any)
+func (s *traceState) current(opts *GeneratorOptions) *TraceEntry {
+	return s.entries[formatJsonPointer(opts.instancePath)]
+}
+
+// Drops entries of values discarded during generation (e.g. by retries for constraints)
+func (s *traceState) collect(value interface{}) Trace {
+	trace := Trace{}
+	for pointer, entry := range s.entries {
+		if pointerExists(value, pointer) {
+			trace[pointer] = *entry
+		}
+	}
+
+	return trace
+}
+
+func pointerExists(value interface{}, pointer string) bool {
+	if pointer == "" {
+		return true
+	}
+
+	for _, segment := range strings.Split(pointer[1:], "/") {
+		segment = unescapeJsonPointerSegment(segment)
+		switch current := value.(type) {
+		case map[string]interface{}:
+			child, ok := current[segment]
+			if !ok {
+				return false
+			}
+
+			value = child
+		case OrderedObject:
+			child, ok := current.Values[segment]
+			if !ok {
+				return false
+			}
+
+			value = child
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(current) {
+				return false
+			}
+
+			value = current[index]
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// Generates the value at the given object key marking it as filler in the trace
+func generateFillerAtPath(opts *GeneratorOptions, segment string, filler TraceFiller, generator Generator) interface{} {
+	if opts.trace == nil {
+		return generateAtPath(opts, segment, generator)
+	}
+
+	opts.instancePath = append(opts.instancePath, segment)
+	defer func() { opts.instancePath = opts.instancePath[:len(opts.instancePath)-1] }()
+
+	entry, done := opts.trace.enter(opts.instancePath)
+	defer done()
+
+	entry.Kind = generatorKind(generator)
+	entry.Filler = filler
+	return generateWithOverrides(opts, generator)
+}
+
+// Records the schema node a value is generated from
+func traceLocation(opts *GeneratorOptions, g locatedGenerator) func() {
+	entry, done := opts.trace.enter(opts.instancePath)
+	entry.Document = g.Document
+	entry.SchemaPath = g.SchemaPath
+	entry.Kind = generatorKind(g.internalGenerator)
+
+	return done
+}
+
+// Records a followed reference and the node it resolved to
+func traceReference(opts *GeneratorOptions, g referenceGenerator, target reference) {
+	entry := opts.trace.current(opts)
+	if entry == nil {
+		return
+	}
+
+	entry.References = append(entry.References, GeneratorReference{Document: g.Document, Path: g.ReferenceStr})
+	entry.Document = target.Document
+	entry.SchemaPath = target.Path
+	entry.Kind = generatorKind(target.Generator)
+}
+
+// Records the branch a "oneOf" or "anyOf" picked
+func traceBranch(opts *GeneratorOptions, g combinationGenerator, index int) {
+	entry := opts.trace.current(opts)
+	if entry == nil {
+		return
+	}
+
+	entry.Branches = append(entry.Branches, TraceBranch{
+		Keyword:    GeneratorKind(g.Type),
+		Document:   entry.Document,
+		SchemaPath: entry.SchemaPath,
+		Index:      index,
+	})
+}
+
+// Records an attempt at satisfying "if" conditions
+func traceIfAttempt(opts *GeneratorOptions) {
+	if opts.trace == nil {
+		return
+	}
+
+	if entry := opts.trace.current(opts); entry != nil {
+		entry.IfAttempts++
+	}
+}
+
+// Records the branch of an "if" a value satisfied
+func traceCondition(opts *GeneratorOptions, g ifConstraint, branch string) {
+	if opts.trace == nil {
+		return
+	}
+
+	if entry := opts.trace.current(opts); entry != nil {
+		entry.Conditions = append(entry.Conditions, TraceCondition{
+			Document:   g.document,
+			SchemaPath: g.schemaPath,
+			Index:      g.index,
+			Branch:     branch,
+		})
+	}
+}
+
+// Records that the constraints of a value could not be satisfied
+func traceUnsatisfied(opts *GeneratorOptions) {
+	if opts.trace == nil {
+		return
+	}
+
+	if entry := opts.trace.current(opts); entry != nil {
+		entry.Unsatisfied = true
+	}
+}
+
+// Records a value given through an override. The returned function must be called once the value is generated
+func traceOverride(opts *GeneratorOptions, generator Generator) func() {
+	entry, done := opts.trace.enter(opts.instancePath)
+	entry.Kind = generatorKind(generator)
+	entry.Overridden = true
+	if located, ok := generator.(locatedGenerator); ok {
+		entry.Document = located.Document
+		entry.SchemaPath = located.SchemaPath
+	}
+
+	return done
+}
+
+// Wraps the generator of a constrained generator so regenerating the value resets its trace entry
+func withTracedRetries(opts *GeneratorOptions, generator Generator) Generator {
+	if opts.trace == nil {
+		return generator
+	}
+
+	entry := opts.trace.current(opts)
+	if entry == nil {
+		return generator
+	}
+
+	return tracedRetryGenerator{
+		internalGenerator: generator,
+		snapshot:          *entry,
+	}
+}
+
+func (g tracedRetryGenerator) Generate(opts *GeneratorOptions) interface{} {
+	if entry := opts.trace.current(opts); entry != nil {
+		entry.Document = g.snapshot.Document
+		entry.SchemaPath = g.snapshot.SchemaPath
+		entry.Kind = g.snapshot.Kind
+		entry.References = append([]GeneratorReference{}, g.snapshot.References...)
+		entry.Branches = append([]TraceBranch{}, g.snapshot.Branches...)
+		entry.Conditions = append([]TraceCondition{}, g.snapshot.Conditions...)
+	}
+
+	return g.internalGenerator.Generate(opts)
+}
+
+func (g tracedRetryGenerator) String() string {
+	return g.internalGenerator.String()
+}
+
+// Returns the kind of a generator without describing any of its children
+func generatorKind(generator Generator) GeneratorKind {
+	switch g := generator.(type) {
+	case locatedGenerator:
+		return generatorKind(g.internalGenerator)
+	case constrainedGenerator:
+		return generatorKind(g.internalGenerator)
+	case hintedGenerator:
+		return generatorKind(g.internalGenerator)
+	case objectGenerator:
+		return GeneratorKindObject
+	case arrayGenerator:
+		return GeneratorKindArray
+	case combinationGenerator:
+		return GeneratorKind(g.Type)
+	case *allOfGenerator:
+		return GeneratorKindAllOf
+	case multipleTypeGenerator:
+		return GeneratorKindMultipleType
+	case referenceGenerator:
+		return GeneratorKindReference
+	}
+
+	return DescribeGenerator(generator).Kind
+}
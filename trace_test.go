@@ -0,0 +1,134 @@
+package chaff_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+	"github.com/ryanolee/go-chaff/rand"
+)
+
+const traceTestSchema = `{
+	"type": "object",
+	"required": ["email", "shape", "size", "unknown"],
+	"minProperties": 6,
+	"properties": {
+		"email": {"$ref": "#/$defs/email"},
+		"shape": {"oneOf": [{"type": "string", "maxLength": 3}, {"type": "integer"}]},
+		"size": {"type": "integer", "if": {"minimum": 50}, "then": {"multipleOf": 7}, "else": {"maximum": 10}},
+		"flags": {"type": "array", "items": {"type": "boolean"}, "minItems": 1}
+	},
+	"$defs": {
+		"email": {"type": "string", "format": "email"}
+	}
+}`
+
+func getTraceTestGenerator(t *testing.T) chaff.RootGenerator {
+	generator, err := chaff.ParseSchemaStringWithDefaults(traceTestSchema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	return generator
+}
+
+func TestTrace(t *testing.T) {
+	t.Parallel()
+	generator := getTraceTestGenerator(t)
+
+	for i := 0; i < 100; i++ {
+		value, trace := generator.GenerateWithTrace(&chaff.GeneratorOptions{Rand: rand.NewRandUtil(int64(i))})
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			t.Fatalf("Expected an object, got %T", value)
+		}
+
+		if trace[""].SchemaPath != "#" || trace[""].Kind != chaff.GeneratorKindObject {
+			t.Errorf("Expected the root to be traced to the root object, got %+v", trace[""])
+		}
+
+		email := trace["/email"]
+		if email.SchemaPath != "#/$defs/email" || email.Kind != chaff.GeneratorKindString {
+			t.Errorf("Expected /email to be traced to its reference target, got %+v", email)
+		}
+
+		if len(email.References) != 1 || email.References[0].Path != "#/$defs/email" {
+			t.Errorf("Expected /email to record the followed reference, got %+v", email.References)
+		}
+
+		shape := trace["/shape"]
+		if len(shape.Branches) != 1 || shape.Branches[0].Keyword != chaff.GeneratorKindOneOf || shape.Branches[0].SchemaPath != "#/properties/shape" {
+			t.Fatalf("Expected /shape to record the oneOf branch, got %+v", shape.Branches)
+		}
+
+		expectedKind := []chaff.GeneratorKind{chaff.GeneratorKindString, chaff.GeneratorKindInteger}[shape.Branches[0].Index]
+		if shape.Kind != expectedKind || shape.SchemaPath != "#/properties/shape/oneOf/"+strconv.Itoa(shape.Branches[0].Index) {
+			t.Errorf("Expected /shape to be traced to the picked branch, got %+v", shape)
+		}
+
+		if trace["/size"].IfAttempts < 1 {
+			t.Errorf("Expected /size to record if attempts, got %+v", trace["/size"])
+		}
+
+		if unknown := trace["/unknown"]; unknown.Filler == "" || unknown.Kind != chaff.GeneratorKindConst {
+			t.Errorf("Expected /unknown to be traced as filler, got %+v", unknown)
+		}
+
+		for key := range object {
+			entry, ok := trace["/"+key]
+			if !ok {
+				t.Errorf("Expected /%s to be traced", key)
+			}
+
+			if strings.HasPrefix(key, "min_filler_") && entry.Filler != chaff.TraceFillerMinProperties {
+				t.Errorf("Expected /%s to be traced as min_filler, got %+v", key, entry)
+			}
+		}
+
+		if flags, ok := object["flags"].([]interface{}); ok {
+			for index := range flags {
+				pointer := "/flags/" + strconv.Itoa(index)
+				if trace[pointer].Kind != chaff.GeneratorKindBoolean {
+					t.Errorf("Expected %s to be traced to a boolean, got %+v", pointer, trace[pointer])
+				}
+			}
+		}
+	}
+}
+
+func TestTraceDropsDiscardedValues(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{
+		"oneOf": [
+			{"type": "object", "properties": {"a": {"type": "string"}}, "required": ["a"], "additionalProperties": false},
+			{"type": "object", "properties": {"b": {"type": "integer"}}, "required": ["b"], "additionalProperties": false}
+		]
+	}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		value, trace := generator.GenerateWithTrace(&chaff.GeneratorOptions{Rand: rand.NewRandUtil(int64(i))})
+		object := value.(map[string]interface{})
+		if len(trace) != len(object)+1 {
+			t.Errorf("Expected a trace entry for the root and each property, got %v for %v", trace.Pointers(), object)
+		}
+
+		if len(trace[""].Branches) != 1 {
+			t.Errorf("Expected exactly one oneOf branch to be recorded, got %+v", trace[""].Branches)
+		}
+	}
+}
+
+func TestTraceOverrides(t *testing.T) {
+	t.Parallel()
+	_, trace := getTraceTestGenerator(t).GenerateWithTrace(&chaff.GeneratorOptions{
+		Overrides: map[string]interface{}{"/email": "someone@example.com"},
+	})
+
+	if !trace["/email"].Overridden {
+		t.Errorf("Expected /email to be marked as overridden, got %+v", trace["/email"])
+	}
+}
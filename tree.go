@@ -0,0 +1,259 @@
+package chaff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ryanolee/go-chaff/internal/util"
+)
+
+type (
+	// The kind of value a GeneratorNode produces or the keyword it implements
+	GeneratorKind string
+
+	// Read only view of a compiled generator. Obtained through RootGenerator.Tree
+	// and intended for tooling such as coverage or documentation generators.
+	GeneratorNode struct {
+		// What the node generates (e.g. "object", "string", "oneOf" or "reference")
+		Kind GeneratorKind `json:"kind"`
+
+		// The document the node was defined in
+		Document string `json:"document,omitempty"`
+
+		// JSON pointer of the schema node within its document (e.g. "#/properties/foo")
+		SchemaPath string `json:"schemaPath,omitempty"`
+
+		// Resolved bounds of the generated value
+		Bounds GeneratorBounds `json:"bounds,omitempty"`
+
+		// String specific settings
+		Format   string `json:"format,omitempty"`
+		Pattern  string `json:"pattern,omitempty"`
+		Provider string `json:"provider,omitempty"`
+		Template string `json:"template,omitempty"`
+
+		// Values produced by "const" and "enum" nodes
+		Values []interface{} `json:"values,omitempty"`
+
+		// Weights of the values or branches given through "x-chaff" hints
+		Weights []float64 `json:"weights,omitempty"`
+
+		// Properties that are always generated for objects
+		Required []string `json:"required,omitempty"`
+
+		// Descriptions of the constraints checked after the value is generated (e.g. "not", "oneOf" or "if")
+		Constraints []string `json:"constraints,omitempty"`
+
+		// Target of "reference" nodes. References are not expanded so the tree is always finite
+		Reference *GeneratorReference `json:"reference,omitempty"`
+
+		// Set for null generators used in place of a schema that could not be compiled
+		Fallback bool `json:"fallback,omitempty"`
+
+		// Errors reported while compiling the schema node (Only set for trees obtained through RootGenerator.Tree)
+		Errors []string `json:"errors,omitempty"`
+
+		// Generators the node delegates to
+		Children []GeneratorChild `json:"children,omitempty"`
+	}
+
+	// Bounds of a generated value. Unset bounds are nil
+	GeneratorBounds struct {
+		Minimum    *float64 `json:"minimum,omitempty"`
+		Maximum    *float64 `json:"maximum,omitempty"`
+		MultipleOf *float64 `json:"multipleOf,omitempty"`
+
+		MinLength *int `json:"minLength,omitempty"`
+		MaxLength *int `json:"maxLength,omitempty"`
+
+		MinItems    *int `json:"minItems,omitempty"`
+		MaxItems    *int `json:"maxItems,omitempty"`
+		MinContains *int `json:"minContains,omitempty"`
+		UniqueItems bool `json:"uniqueItems,omitempty"`
+
+		MinProperties *int `json:"minProperties,omitempty"`
+		MaxProperties *int `json:"maxProperties,omitempty"`
+	}
+
+	// A child of a GeneratorNode and how it relates to its parent
+	GeneratorChild struct {
+		// The keyword the child was compiled from (e.g. "properties", "items", "oneOf" or "then")
+		Relation string `json:"relation"`
+
+		// Property name, pattern or index of the child within the keyword (Empty if not applicable)
+		Key string `json:"key,omitempty"`
+
+		Node *GeneratorNode `json:"node"`
+	}
+
+	// The schema node a reference points to
+	GeneratorReference struct {
+		Document string `json:"document"`
+		Path     string `json:"path"`
+	}
+
+	// Records the schema node a generator was compiled from
+	locatedGenerator struct {
+		internalGenerator Generator
+		Document          string
+		SchemaPath        string
+	}
+
+	// Implemented by generators that can describe themselves as a GeneratorNode
+	describableGenerator interface {
+		describe(node *GeneratorNode)
+	}
+
+	// Implemented by constraints that can describe themselves on a GeneratorNode
+	describableConstraint interface {
+		describeConstraint(node *GeneratorNode)
+	}
+)
+
+const (
+	GeneratorKindObject       GeneratorKind = "object"
+	GeneratorKindArray        GeneratorKind = "array"
+	GeneratorKindString       GeneratorKind = "string"
+	GeneratorKindNumber       GeneratorKind = "number"
+	GeneratorKindInteger      GeneratorKind = "integer"
+	GeneratorKindBoolean      GeneratorKind = "boolean"
+	GeneratorKindNull         GeneratorKind = "null"
+	GeneratorKindConst        GeneratorKind = "const"
+	GeneratorKindEnum         GeneratorKind = "enum"
+	GeneratorKindOneOf        GeneratorKind = "oneOf"
+	GeneratorKindAnyOf        GeneratorKind = "anyOf"
+	GeneratorKindAllOf        GeneratorKind = "allOf"
+	GeneratorKindMultipleType GeneratorKind = "multipleType"
+	GeneratorKindReference    GeneratorKind = "reference"
+	GeneratorKindUnknown      GeneratorKind = "unknown"
+)
+
+// Returns a read only tree describing the compiled generator.
+// Any "$defs" and "definitions" are included as children of the root node
+// and errors reported while parsing are attached to the nodes they were reported for.
+func (g RootGenerator) Tree() *GeneratorNode {
+	node := DescribeGenerator(g.Generator)
+	node.addChildren("$defs", g.Defs)
+	node.addChildren("definitions", g.Definitions)
+	if g.Metadata != nil && g.Metadata.Errors != nil {
+		node.attachErrors(g.Metadata.Errors)
+	}
+
+	return node
+}
+
+// Returns a read only tree describing the given generator
+func DescribeGenerator(generator Generator) *GeneratorNode {
+	node := &GeneratorNode{}
+	node.describe(generator)
+	return node
+}
+
+// Visits the node and all of its descendants depth first, parents before children.
+// Returning false from the visitor skips the children of the visited node.
+func (n *GeneratorNode) Walk(visit func(node *GeneratorNode) bool) {
+	if !visit(n) {
+		return
+	}
+
+	for _, child := range n.Children {
+		child.Node.Walk(visit)
+	}
+}
+
+func (n *GeneratorNode) String() string {
+	if n.SchemaPath == "" {
+		return string(n.Kind)
+	}
+
+	return fmt.Sprintf("%s[%s]", n.Kind, n.SchemaPath)
+}
+
+func (n *GeneratorNode) describe(generator Generator) {
+	describable, ok := generator.(describableGenerator)
+	if !ok || generator == nil {
+		n.Kind = GeneratorKindUnknown
+		return
+	}
+
+	describable.describe(n)
+}
+
+// Describes the generator as a child of the node. Nil generators are skipped
+func (n *GeneratorNode) addChild(relation string, key string, generator Generator) {
+	if generator == nil {
+		return
+	}
+
+	n.Children = append(n.Children, GeneratorChild{
+		Relation: relation,
+		Key:      key,
+		Node:     DescribeGenerator(generator),
+	})
+}
+
+// Describes the generators of a map as children in key order
+func (n *GeneratorNode) addChildren(relation string, generators map[string]Generator) {
+	keys := make([]string, 0, len(generators))
+	for key := range generators {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		n.addChild(relation, key, generators[key])
+	}
+}
+
+// Attaches each error to the deepest node in the same document whose schema path prefixes the path of the error.
+// Errors that do not belong to any node (e.g. those from external documents) are attached to the root
+func (n *GeneratorNode) attachErrors(errors *errorCollection) {
+	documents := util.MapKeysToStringSlice(&errors.Errors)
+	sort.Strings(documents)
+	for _, document := range documents {
+		documentErrors := errors.Errors[document]
+		paths := util.MapKeysToStringSlice(&documentErrors)
+		sort.Strings(paths)
+		for _, path := range paths {
+			target := n
+			n.Walk(func(node *GeneratorNode) bool {
+				inNode := path == node.SchemaPath || strings.HasPrefix(path, node.SchemaPath+"/")
+				if node.Document == document && inNode && len(node.SchemaPath) > len(target.SchemaPath) {
+					target = node
+				}
+
+				return true
+			})
+
+			target.Errors = append(target.Errors, fmt.Sprintf("%s -> %s: %s", document, path, documentErrors[path]))
+		}
+	}
+}
+
+func intBound(value int) *int {
+	if value == 0 {
+		return nil
+	}
+
+	return &value
+}
+
+func (g locatedGenerator) Generate(opts *GeneratorOptions) interface{} {
+	if opts.trace != nil {
+		done := traceLocation(opts, g)
+		defer done()
+	}
+
+	return g.internalGenerator.Generate(opts)
+}
+
+func (g locatedGenerator) String() string {
+	return g.internalGenerator.String()
+}
+
+func (g locatedGenerator) describe(node *GeneratorNode) {
+	node.describe(g.internalGenerator)
+	node.Document = g.Document
+	node.SchemaPath = g.SchemaPath
+}
@@ -0,0 +1,141 @@
+package chaff_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ryanolee/go-chaff"
+)
+
+const treeTestSchema = `{
+	"type": "object",
+	"$defs": {"id": {"type": "string", "format": "uuid"}},
+	"properties": {
+		"id": {"$ref": "#/$defs/id"},
+		"tags": {"type": "array", "items": {"type": "string", "maxLength": 5}, "minItems": 1, "uniqueItems": true},
+		"shape": {"oneOf": [{"type": "integer", "minimum": 1, "maximum": 5}, {"enum": ["a", "b"]}]},
+		"word": {"type": "string", "not": {"pattern": "^a"}},
+		"kind": {"type": "string", "enum": ["x", "y"]}
+	},
+	"required": ["id"],
+	"if": {"properties": {"kind": {"const": "x"}}},
+	"then": {"properties": {"extra": {"type": "boolean"}}}
+}`
+
+func TestTree(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(treeTestSchema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	nodes := map[string]*chaff.GeneratorNode{}
+	generator.Tree().Walk(func(node *chaff.GeneratorNode) bool {
+		nodes[node.SchemaPath] = node
+		return true
+	})
+
+	expectedKinds := map[string]chaff.GeneratorKind{
+		"#":                          chaff.GeneratorKindObject,
+		"#/$defs/id":                 chaff.GeneratorKindString,
+		"#/properties/id":            chaff.GeneratorKindReference,
+		"#/properties/tags":          chaff.GeneratorKindArray,
+		"#/properties/tags/items":    chaff.GeneratorKindString,
+		"#/properties/shape":         chaff.GeneratorKindOneOf,
+		"#/properties/shape/oneOf/0": chaff.GeneratorKindInteger,
+		"#/properties/shape/oneOf/1": chaff.GeneratorKindEnum,
+		"#/properties/word":          chaff.GeneratorKindString,
+		"#/then":                     chaff.GeneratorKindObject,
+		"#/then/properties/extra":    chaff.GeneratorKindBoolean,
+	}
+
+	for path, kind := range expectedKinds {
+		node, ok := nodes[path]
+		if !ok {
+			t.Errorf("Expected a node at %s", path)
+			continue
+		}
+
+		if node.Kind != kind {
+			t.Errorf("Expected %s to be a %s node, got %s", path, kind, node.Kind)
+		}
+	}
+
+	if ref := nodes["#/properties/id"].Reference; ref == nil || ref.Path != "#/$defs/id" {
+		t.Errorf("Expected the reference target to be #/$defs/id, got %v", ref)
+	}
+
+	if format := nodes["#/$defs/id"].Format; format != "uuid" {
+		t.Errorf("Expected the uuid format to be described, got %s", format)
+	}
+
+	tags := nodes["#/properties/tags"].Bounds
+	if tags.MinItems == nil || *tags.MinItems != 1 || !tags.UniqueItems {
+		t.Errorf("Expected array bounds to be described, got %+v", tags)
+	}
+
+	shape := nodes["#/properties/shape/oneOf/0"].Bounds
+	if shape.Minimum == nil || *shape.Minimum != 1 || shape.Maximum == nil || *shape.Maximum != 5 {
+		t.Errorf("Expected number bounds to be described, got %+v", shape)
+	}
+
+	if !reflect.DeepEqual(nodes["#/properties/word"].Constraints, []string{"not Regex: ^a"}) {
+		t.Errorf("Expected the not constraint to be described, got %v", nodes["#/properties/word"].Constraints)
+	}
+
+	if len(nodes["#/properties/shape"].Constraints) != 1 || len(nodes["#"].Constraints) != 1 {
+		t.Errorf("Expected the oneOf and if constraints to be described, got %v and %v", nodes["#/properties/shape"].Constraints, nodes["#"].Constraints)
+	}
+}
+
+func TestTreeWalkSkipsChildren(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(treeTestSchema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	visited := []string{}
+	generator.Tree().Walk(func(node *chaff.GeneratorNode) bool {
+		visited = append(visited, node.SchemaPath)
+		return node.SchemaPath == "#"
+	})
+
+	// The root and its direct children in order: properties, then, $defs
+	expected := []string{
+		"#", "#/properties/id", "#/properties/kind", "#/properties/shape", "#/properties/tags",
+		"#/properties/word", "#/then", "#/$defs/id",
+	}
+
+	if !reflect.DeepEqual(visited, expected) {
+		t.Fatalf("Expected to visit %v, got %v", expected, visited)
+	}
+}
+
+func TestTreeErrorsAttachToMatchingNode(t *testing.T) {
+	t.Parallel()
+	generator, err := chaff.ParseSchemaStringWithDefaults(`{
+		"type": "object",
+		"properties": {
+			"a": {"type": "string"},
+			"ab": {"type": "string", "pattern": "(["}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %s", err)
+	}
+
+	nodes := map[string]*chaff.GeneratorNode{}
+	generator.Tree().Walk(func(node *chaff.GeneratorNode) bool {
+		nodes[node.SchemaPath] = node
+		return true
+	})
+
+	if errors := nodes["#/properties/a"].Errors; len(errors) != 0 {
+		t.Errorf("Expected no errors on #/properties/a, got %v", errors)
+	}
+
+	if errors := nodes["#"].Errors; len(errors) != 1 {
+		t.Errorf("Expected the error of #/properties/ab to be attached to the root, got %v", errors)
+	}
+}